@@ -0,0 +1,95 @@
+package stairs
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SampleWithBias draws a single index as if the weight of each index in
+// bias were multiplied by its value; indices not in bias keep their weight.
+// Multipliers must be non-negative, and zero suppresses an index for this
+// draw. The CDF itself is unchanged. Each call takes O(n) time.
+func (c *CDF) SampleWithBias(bias map[int]float64) (int, error) {
+	weight := func(i int) float64 {
+		if i == 0 {
+			return float64(c.items[0].Weight)
+		}
+		return float64(c.items[i].Weight - c.items[i-1].Weight)
+	}
+	index := func(i int) int { return c.items[i].Index }
+
+	return biasedPick(len(c.items), weight, index, bias, c.r)
+}
+
+// SampleWithBias draws a single index as if the weight of each index in
+// bias were multiplied by its value; indices not in bias keep their weight.
+// Multipliers must be non-negative, and zero suppresses an index for this
+// draw. The CDF itself is unchanged. Each call takes O(n) time.
+func (c *CDFFloat) SampleWithBias(bias map[int]float64) (int, error) {
+	weight := func(i int) float64 {
+		if i == 0 {
+			return c.items[0].Weight
+		}
+		return c.items[i].Weight - c.items[i-1].Weight
+	}
+	index := func(i int) int { return c.items[i].Index }
+
+	return biasedPick(len(c.items), weight, index, bias, c.r)
+}
+
+// biasedPick chooses one of n items in proportion to its weight times its
+// multiplier in bias, and returns its index.
+func biasedPick(n int, weight func(int) float64, index func(int) int, bias map[int]float64, r *rand.Rand) (int, error) {
+	if n == 0 {
+		return 0, ErrEmpty
+	}
+
+	for _, m := range bias {
+		if !(m >= 0) || math.IsInf(m, 1) {
+			return 0, ErrOutOfRange
+		}
+	}
+
+	effective := func(i int) float64 {
+		if m, ok := bias[index(i)]; ok {
+			return weight(i) * m
+		}
+		return weight(i)
+	}
+
+	var total kahanSum
+	for i := 0; i < n; i++ {
+		total.add(effective(i))
+	}
+
+	// Make sure the biased weights didn't overflow, which
+	// can leave the sum infinite or NaN
+	if !(total.sum <= math.MaxFloat64) {
+		return 0, ErrWeightOverflow
+	}
+
+	// Every item was suppressed
+	if !(total.sum > 0) {
+		return 0, ErrNonPositiveWeight
+	}
+
+	// Picking a random number in the range [0, total weight)
+	num := r.Float64() * total.sum
+
+	// Walk the items until the running sum passes the number,
+	// skipping suppressed items so rounding can't land on them
+	last := -1
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		w := effective(i)
+		if w <= 0 {
+			continue
+		}
+		last = i
+		if sum += w; sum > num {
+			return index(i), nil
+		}
+	}
+
+	return index(last), nil
+}
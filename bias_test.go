@@ -0,0 +1,76 @@
+package stairs
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestSampleWithBias checks that boosting and suppressing
+// indices changes a single draw's distribution.
+func TestSampleWithBias(t *testing.T) {
+	c, err := WeightedItems{{1, 0}, {1, 1}, {2, 2}}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Reseed(12)
+
+	// Suppress index 2 and make index 0 three times as likely as index 1
+	bias := map[int]float64{0: 3, 2: 0}
+
+	const draws = 20000
+	counts := make([]int, 3)
+	for i := 0; i < draws; i++ {
+		index, err := c.SampleWithBias(bias)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[index]++
+	}
+
+	if counts[2] != 0 {
+		t.Errorf("suppressed index drawn %d times", counts[2])
+	}
+
+	if observed := float64(counts[0]) / draws; math.Abs(observed-0.75) > 0.015 {
+		t.Errorf("boosted index drawn %v of the time, want 0.75", observed)
+	}
+}
+
+// TestSampleWithBiasFloat checks that suppressing every index
+// but one always returns that index.
+func TestSampleWithBiasFloat(t *testing.T) {
+	c, err := buildWeightedFloatArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		index, err := c.SampleWithBias(map[int]float64{0: 0, 2: 0})
+		if err != nil || index != 1 {
+			t.Fatalf("got %d, %v, want 1", index, err)
+		}
+	}
+}
+
+// TestSampleWithBiasInvalid checks that negative multipliers,
+// suppressing everything and overflowing the total are rejected.
+func TestSampleWithBiasInvalid(t *testing.T) {
+	c, err := buildWeightedArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.SampleWithBias(map[int]float64{1: -1}); !errors.Is(err, ErrOutOfRange) {
+		t.Error("accepted a negative multiplier")
+	}
+
+	if _, err := c.SampleWithBias(map[int]float64{0: 0, 1: 0, 2: 0}); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Error("sampled with every index suppressed")
+	}
+
+	huge := map[int]float64{0: math.MaxFloat64, 1: math.MaxFloat64, 2: math.MaxFloat64}
+	if _, err := c.SampleWithBias(huge); !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("got %v for an overflowed total, want ErrWeightOverflow", err)
+	}
+}
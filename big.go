@@ -0,0 +1,102 @@
+package stairs
+
+import (
+	"math/big"
+	"math/rand"
+	"sort"
+)
+
+// WeightedItemBig contains the arbitrary-precision weight
+// for the item and the index it represents in the
+// original array.
+type WeightedItemBig struct {
+	// The relative weight assigned to the item
+	Weight *big.Int `json:"weight"`
+	// Index is the location in the original array
+	// for the item
+	Index int `json:"index"`
+}
+
+// WeightedItemsBig is an array of WeightedItemBig items. Weights and
+// their total can be as large as needed, so it suits weights from
+// sources that could overflow an int when summed. Sampling is slower
+// than WeightedItems, so prefer that when the total fits.
+type WeightedItemsBig []WeightedItemBig
+
+var _ Distribution = WeightedItemsBig(nil)
+
+// Len returns the number of items.
+func (s WeightedItemsBig) Len() int {
+	return len(s)
+}
+
+// Validate checks that the array can be built into a CDF: it must be
+// non-empty, every weight must be set and positive, and no two items
+// may share an index.
+func (s WeightedItemsBig) Validate() error {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return ErrEmpty
+	}
+
+	// Make sure all items have positive weight
+	for _, item := range s {
+		if item.Weight == nil || item.Weight.Sign() <= 0 {
+			return ErrNonPositiveWeight
+		}
+	}
+
+	// Reject items that point to the same index
+	if hasDuplicateIndex(len(s), func(i int) int { return s[i].Index }) {
+		return ErrDuplicateIndex
+	}
+
+	return nil
+}
+
+// BuildCDF converts an arbitrary-precision weighted array into a CDF and
+// returns a function that selects random indices according to the weights.
+// The weights are copied, so later changes to them don't affect sampling.
+func (s WeightedItemsBig) BuildCDF() (func() int, error) {
+	return s.BuildCDFWithRand(newRand())
+}
+
+// BuildCDFWithRand works like BuildCDF, but draws from the given
+// random number generator instead of seeding its own.
+// Passing a fixed-seed generator makes the returned sequence reproducible.
+func (s WeightedItemsBig) BuildCDFWithRand(r *rand.Rand) (func() int, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Sort a copy ascending by weight, then by index
+	c := append(make(WeightedItemsBig, 0, len(s)), s...)
+	sort.Slice(c, func(i, j int) bool {
+		if cmp := c[i].Weight.Cmp(c[j].Weight); cmp != 0 {
+			return cmp < 0
+		}
+		return c[i].Index < c[j].Index
+	})
+
+	// Accumulate into new values so the caller's weights are untouched
+	sum := new(big.Int)
+	for i := range c {
+		sum.Add(sum, c[i].Weight)
+		c[i].Weight = new(big.Int).Set(sum)
+	}
+
+	total := c[len(c)-1].Weight
+	one := big.NewInt(1)
+
+	return func() int {
+		// Picking a random number in the range [1, max weight + 1)
+		num := new(big.Int).Rand(r, total)
+		num.Add(num, one)
+
+		// Find the first item whose cumulative weight reaches the number
+		i := sort.Search(len(c), func(i int) bool {
+			return c[i].Weight.Cmp(num) >= 0
+		})
+		return c[i].Index
+	}, nil
+}
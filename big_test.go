@@ -0,0 +1,55 @@
+package stairs
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// TestBuildBig checks that weights far beyond the int range
+// are sampled in proportion.
+func TestBuildBig(t *testing.T) {
+	huge := new(big.Int).Lsh(big.NewInt(1), 100)
+
+	w := WeightedItemsBig{
+		{new(big.Int).Set(huge), 0},
+		{new(big.Int).Mul(huge, big.NewInt(3)), 1},
+	}
+
+	f, err := w.BuildCDFWithRand(rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Index 1 holds three quarters of the weight
+	counts := SampleHistogram(f, 10000, 2)
+	if counts[1] < 7000 || counts[1] > 8000 {
+		t.Errorf("got %v", counts)
+	}
+
+	// Building mustn't change the caller's weights
+	if w[0].Weight.Cmp(huge) != 0 {
+		t.Error("modified the input weights")
+	}
+}
+
+// TestBigValidate checks that missing, non-positive
+// and repeated entries are rejected.
+func TestBigValidate(t *testing.T) {
+	tests := []struct {
+		s    WeightedItemsBig
+		want error
+	}{
+		{nil, ErrEmpty},
+		{WeightedItemsBig{{nil, 0}}, ErrNonPositiveWeight},
+		{WeightedItemsBig{{big.NewInt(-1), 0}}, ErrNonPositiveWeight},
+		{WeightedItemsBig{{big.NewInt(1), 0}, {big.NewInt(2), 0}}, ErrDuplicateIndex},
+	}
+
+	for _, test := range tests {
+		if _, err := test.s.BuildCDF(); !errors.Is(err, test.want) {
+			t.Errorf("%v: got %v, want %v", test.s, err, test.want)
+		}
+	}
+}
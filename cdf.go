@@ -0,0 +1,405 @@
+package stairs
+
+import (
+	"math/rand"
+	"time"
+)
+
+// CDF is a built integer distribution that can be sampled repeatedly.
+// Unlike the function returned by BuildCDF, its random number
+// generator can be reseeded without rebuilding the distribution.
+//
+// A CDF is not safe for concurrent use.
+type CDF struct {
+	// items holds the sorted, accumulated weights
+	items WeightedItems
+	r     *rand.Rand
+	// seed is the last seed r was given, for SampleByDrawIndex
+	seed int64
+}
+
+// CDFFloat is a built floating-point distribution that can be sampled
+// repeatedly. Unlike the function returned by BuildCDF, its random number
+// generator can be reseeded without rebuilding the distribution.
+//
+// A CDFFloat is not safe for concurrent use.
+type CDFFloat struct {
+	// items holds the sorted, accumulated weights
+	items WeightedItemsFloat
+	r     *rand.Rand
+	// seed is the last seed r was given, for SampleByDrawIndex
+	seed int64
+}
+
+// Build converts a weighted array into a CDF.
+func (s WeightedItems) Build() (*CDF, error) {
+	seed := time.Now().UnixNano()
+
+	c, err := s.build(rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return nil, err
+	}
+	c.seed = seed
+	return c, nil
+}
+
+// Build converts a floating-point weighted array into a CDFFloat.
+func (s WeightedItemsFloat) Build() (*CDFFloat, error) {
+	seed := time.Now().UnixNano()
+
+	c, err := s.build(rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return nil, err
+	}
+	c.seed = seed
+	return c, nil
+}
+
+// BuildCDFChecked works like BuildCDF, but the returned function
+// reports problems as an error instead of panicking.
+//
+// Every weight and the total are validated when building, so the function
+// from BuildCDF can't panic either; this form suits callers who want
+// explicit error handling at every call site.
+func (s WeightedItems) BuildCDFChecked() (func() (int, error), error) {
+	c, err := s.Build()
+	if err != nil {
+		return nil, err
+	}
+	return c.TrySample, nil
+}
+
+// BuildCDFChecked works like BuildCDF, but the returned function
+// reports problems as an error instead of panicking.
+//
+// Every weight and the total are validated when building, so the function
+// from BuildCDF can't panic either; this form suits callers who want
+// explicit error handling at every call site.
+func (s WeightedItemsFloat) BuildCDFChecked() (func() (int, error), error) {
+	c, err := s.Build()
+	if err != nil {
+		return nil, err
+	}
+	return c.TrySample, nil
+}
+
+// build converts a weighted array into a CDF that draws from r.
+func (s WeightedItems) build(r *rand.Rand) (*CDF, error) {
+	c, err := s.cumulative()
+	if err != nil {
+		return nil, err
+	}
+	return &CDF{items: c, r: r}, nil
+}
+
+// build converts a weighted array into a CDFFloat that draws from r.
+func (s WeightedItemsFloat) build(r *rand.Rand) (*CDFFloat, error) {
+	c, err := s.cumulative()
+	if err != nil {
+		return nil, err
+	}
+	return &CDFFloat{items: c, r: r}, nil
+}
+
+// Sample returns the original index of a random item,
+// chosen according to the weights.
+func (c *CDF) Sample() int {
+	return c.items[c.draw()].Index
+}
+
+// draw returns the sorted position of a random item.
+func (c *CDF) draw() int {
+	// Picking a random number in the range [1, max weight + 1)
+	num := c.r.Intn(c.total()) + 1
+
+	return c.search(num)
+}
+
+// sampler returns the fastest function that samples like Sample.
+// One item is always selected, and two items (an A/B split)
+// only need a single comparison.
+func (c *CDF) sampler() func() int {
+	switch len(c.items) {
+	case 1:
+		return c.sampleOne
+	case 2:
+		return c.sampleTwo
+	}
+	return c.Sample
+}
+
+// sampleOne works like Sample for a CDF of exactly one item,
+// returning its index without drawing a random number.
+func (c *CDF) sampleOne() int {
+	return c.items[0].Index
+}
+
+// sampleTwo works like Sample for a CDF of exactly two items,
+// comparing against the first cumulative weight instead of searching.
+func (c *CDF) sampleTwo() int {
+	num := c.r.Intn(c.items[1].Weight) + 1
+
+	if num <= c.items[0].Weight {
+		return c.items[0].Index
+	}
+	return c.items[1].Index
+}
+
+// SampleAt returns the original index of the item whose share of the
+// cumulative distribution contains u, which must be in [0, 1).
+// It uses no randomness, so sampling can be driven by an external
+// sequence such as a quasi-random or Sobol generator.
+func (c *CDF) SampleAt(u float64) (int, error) {
+	if len(c.items) == 0 {
+		return 0, ErrEmpty
+	}
+
+	if !(u >= 0 && u < 1) {
+		return 0, ErrOutOfRange
+	}
+
+	// Map u onto the range [1, max weight], guarding against
+	// rounding up past the end for very large totals
+	num := int(u*float64(c.total())) + 1
+	if num > c.total() {
+		num = c.total()
+	}
+
+	return c.items[c.search(num)].Index, nil
+}
+
+// TrySample works like Sample, but returns an error instead of
+// panicking if the CDF is empty, such as a zero CDF{} that
+// wasn't made by Build.
+func (c *CDF) TrySample() (int, error) {
+	if len(c.items) == 0 {
+		return 0, ErrEmpty
+	}
+	if c.r == nil {
+		c.r = newRand()
+	}
+	return c.Sample(), nil
+}
+
+// Reseed resets the random number generator to the given seed.
+// Two CDFs built from the same array and reseeded with the same
+// value return the same sequence of indices.
+func (c *CDF) Reseed(seed int64) {
+	c.r.Seed(seed)
+	c.seed = seed
+}
+
+// Seed returns the seed the CDF's generator was last given, by Build or
+// Reseed. Recording it alongside the CDF's weights is enough to replay
+// SampleByDrawIndex; it says nothing about how far Sample has advanced.
+func (c *CDF) Seed() int64 {
+	return c.seed
+}
+
+// SampleByDrawIndex returns the index selected by draw number n, which
+// depends only on n, the CDF's weights and its seed, not on how many
+// draws came before. Draw 5 for a given seed is always the same, so
+// draws can be replayed and audited one at a time. It doesn't use or
+// advance the generator that Sample draws from.
+func (c *CDF) SampleByDrawIndex(n int) (int, error) {
+	if n < 0 {
+		return 0, ErrOutOfRange
+	}
+	return c.SampleAt(drawValue(c.seed, n))
+}
+
+// total returns the largest cumulative weight.
+func (c *CDF) total() int {
+	return c.items[len(c.items)-1].Weight
+}
+
+// search returns the position of the item whose cumulative
+// range contains num, which must be in [1, total weight].
+func (c *CDF) search(num int) int {
+	s := c.items
+
+	// Binary search! Look for the number generated.
+	// Right and left are the bounds for the binary search
+	right := len(s) - 1
+	left := 0
+
+	for {
+		// check the middle of the bounds
+		m := (left + right) / 2 // m stands for middle
+		valm := s[m].Weight
+
+		if valm == num { // exact match
+			return m
+		} else if valm < num {
+			// Middle item is less than number
+
+			if m == len(s)-1 {
+				// only option is rightmost item
+				return m
+			} else if s[m+1].Weight > num {
+				// return the right item when
+				// the search is finished
+				// and left between two items.
+				return m + 1
+			}
+			// bring left bound to the middle
+			left = m + 1
+		} else {
+			// Middle item is more than number
+
+			if m == 0 || s[m-1].Weight < num {
+				// Can't move left, so return the middle.
+				return m
+			}
+			// bring right bound to the middle
+			right = m - 1
+		}
+	}
+}
+
+// Sample returns the original index of a random item,
+// chosen according to the weights.
+func (c *CDFFloat) Sample() int {
+	return c.items[c.draw()].Index
+}
+
+// draw returns the sorted position of a random item.
+func (c *CDFFloat) draw() int {
+	// Picking a random number in the range [0, max weight)
+	num := c.r.Float64() * c.total()
+
+	return c.search(num)
+}
+
+// sampler returns the fastest function that samples like Sample.
+// One item is always selected, and two items (an A/B split)
+// only need a single comparison.
+func (c *CDFFloat) sampler() func() int {
+	switch len(c.items) {
+	case 1:
+		return c.sampleOne
+	case 2:
+		return c.sampleTwo
+	}
+	return c.Sample
+}
+
+// sampleOne works like Sample for a CDFFloat of exactly one item,
+// returning its index without drawing a random number.
+func (c *CDFFloat) sampleOne() int {
+	return c.items[0].Index
+}
+
+// sampleTwo works like Sample for a CDFFloat of exactly two items,
+// comparing against the first cumulative weight instead of searching.
+func (c *CDFFloat) sampleTwo() int {
+	num := c.r.Float64() * c.items[1].Weight
+
+	if num < c.items[0].Weight {
+		return c.items[0].Index
+	}
+	return c.items[1].Index
+}
+
+// SampleAt returns the original index of the item whose share of the
+// cumulative distribution contains u, which must be in [0, 1).
+// It uses no randomness, so sampling can be driven by an external
+// sequence such as a quasi-random or Sobol generator.
+func (c *CDFFloat) SampleAt(u float64) (int, error) {
+	if len(c.items) == 0 {
+		return 0, ErrEmpty
+	}
+
+	if !(u >= 0 && u < 1) {
+		return 0, ErrOutOfRange
+	}
+
+	return c.items[c.search(u*c.total())].Index, nil
+}
+
+// TrySample works like Sample, but returns an error instead of
+// panicking if the CDF is empty, such as a zero CDFFloat{} that
+// wasn't made by Build.
+func (c *CDFFloat) TrySample() (int, error) {
+	if len(c.items) == 0 {
+		return 0, ErrEmpty
+	}
+	if c.r == nil {
+		c.r = newRand()
+	}
+	return c.Sample(), nil
+}
+
+// Reseed resets the random number generator to the given seed.
+// Two CDFs built from the same array and reseeded with the same
+// value return the same sequence of indices.
+func (c *CDFFloat) Reseed(seed int64) {
+	c.r.Seed(seed)
+	c.seed = seed
+}
+
+// Seed returns the seed the CDF's generator was last given, by Build or
+// Reseed. Recording it alongside the CDF's weights is enough to replay
+// SampleByDrawIndex; it says nothing about how far Sample has advanced.
+func (c *CDFFloat) Seed() int64 {
+	return c.seed
+}
+
+// SampleByDrawIndex returns the index selected by draw number n, which
+// depends only on n, the CDF's weights and its seed, not on how many
+// draws came before. Draw 5 for a given seed is always the same, so
+// draws can be replayed and audited one at a time. It doesn't use or
+// advance the generator that Sample draws from.
+func (c *CDFFloat) SampleByDrawIndex(n int) (int, error) {
+	if n < 0 {
+		return 0, ErrOutOfRange
+	}
+	return c.SampleAt(drawValue(c.seed, n))
+}
+
+// total returns the largest cumulative weight.
+func (c *CDFFloat) total() float64 {
+	return c.items[len(c.items)-1].Weight
+}
+
+// search returns the position of the first item whose cumulative
+// weight is greater than num, which must be in [0, total weight).
+// Each item owns the range from the previous total up to, but not
+// including, its own, so no tolerance is needed however large or
+// small the weights are.
+func (c *CDFFloat) search(num float64) int {
+	s := c.items
+
+	// Binary search! Narrow the bounds down to the first
+	// item whose cumulative weight is past the number.
+	right := len(s) - 1
+	left := 0
+
+	for left < right {
+		m := (left + right) / 2 // m stands for middle
+
+		if s[m].Weight > num {
+			// Middle item could be the first past the number,
+			// so bring right bound to the middle
+			right = m
+		} else {
+			// Middle item ends at or before the number
+			left = m + 1
+		}
+	}
+
+	return left
+}
+
+// drawValue hashes a seed and draw number into a uniform value in [0, 1),
+// using the SplitMix64 mixing function over the draw's position in the
+// seed's sequence.
+func drawValue(seed int64, n int) float64 {
+	z := uint64(seed) + uint64(n+1)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	z ^= z >> 31
+
+	// Keep the top 53 bits, which a float64 holds exactly
+	return float64(z>>11) * 0x1p-53
+}
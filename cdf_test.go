@@ -0,0 +1,265 @@
+package stairs
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestCDFReseed checks that reseeding a CDF replays
+// the same sequence of indices.
+func TestCDFReseed(t *testing.T) {
+	c, err := buildWeightedArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Reseed(99)
+	first := drawCDF(c.Sample, 50)
+
+	c.Reseed(99)
+	second := drawCDF(c.Sample, 50)
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("draw %d: got %d after reseeding, want %d", i, second[i], first[i])
+		}
+	}
+}
+
+// TestCDFFloatReseed checks that reseeding a floating-point
+// CDF replays the same sequence of indices.
+func TestCDFFloatReseed(t *testing.T) {
+	c, err := buildWeightedFloatArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Reseed(99)
+	first := drawCDF(c.Sample, 50)
+
+	c.Reseed(99)
+	second := drawCDF(c.Sample, 50)
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("draw %d: got %d after reseeding, want %d", i, second[i], first[i])
+		}
+	}
+}
+
+// TestCDFMatchesBuildCDF checks that a CDF and the function from
+// BuildCDFWithRand agree when given the same seed.
+func TestCDFMatchesBuildCDF(t *testing.T) {
+	w := buildWeightedArray()
+
+	f, err := w.BuildCDFWithRand(rand.New(rand.NewSource(4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := w.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Reseed(4)
+
+	for i := 0; i < 100; i++ {
+		if f() != c.Sample() {
+			t.Fatal("CDF and BuildCDF disagree")
+		}
+	}
+}
+
+// drawCDF collects n draws from a sample function.
+func drawCDF(f func() int, n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = f()
+	}
+	return out
+}
+
+// TestTrySampleEmpty checks that sampling an unbuilt
+// CDF returns an error instead of panicking.
+func TestTrySampleEmpty(t *testing.T) {
+	var c CDF
+	if _, err := c.TrySample(); !errors.Is(err, ErrEmpty) {
+		t.Error("sampled an empty CDF")
+	}
+
+	var cf CDFFloat
+	if _, err := cf.TrySample(); !errors.Is(err, ErrEmpty) {
+		t.Error("sampled an empty CDFFloat")
+	}
+}
+
+// TestBuildCDFChecked checks that the checked sampler
+// returns valid indices without errors.
+func TestBuildCDFChecked(t *testing.T) {
+	w := buildWeightedArray()
+
+	f, err := w.BuildCDFChecked()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		index, err := f()
+		if err != nil || index < 0 || index >= len(w) {
+			t.Fatalf("got %d, %v", index, err)
+		}
+	}
+}
+
+// TestInvalidFloatTotals checks that float weights which would
+// make the sampler misbehave are rejected when building.
+func TestInvalidFloatTotals(t *testing.T) {
+	huge := WeightedItemsFloat{{math.MaxFloat64, 0}, {math.MaxFloat64, 1}}
+	if _, err := huge.BuildCDFChecked(); !errors.Is(err, ErrWeightOverflow) {
+		t.Error("accepted an infinite total")
+	}
+
+	// With more items the overflowed sum becomes NaN, not infinity
+	for _, w := range []WeightedItemsFloat{
+		{{math.MaxFloat64, 0}, {math.MaxFloat64, 1}, {math.MaxFloat64, 2}},
+		{{1, 0}, {math.Inf(1), 1}, {math.Inf(1), 2}},
+	} {
+		if _, err := w.BuildCDFChecked(); !errors.Is(err, ErrWeightOverflow) {
+			t.Errorf("%v: accepted a total that isn't finite", w)
+		}
+	}
+
+	nan := WeightedItemsFloat{{1, 0}, {math.NaN(), 1}, {2, 2}}
+	if _, err := nan.BuildCDFChecked(); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Error("accepted a NaN weight")
+	}
+}
+
+// TestSampleAt checks that quantiles map onto
+// each item's share of the distribution.
+func TestSampleAt(t *testing.T) {
+	c, err := buildWeightedArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Weights 1, 2 and 5 split [0, 1) at 1/8 and 3/8
+	cases := map[float64]int{0: 0, 0.1: 0, 0.125: 1, 0.3: 1, 0.375: 2, 0.99: 2}
+	for u, want := range cases {
+		if got, err := c.SampleAt(u); err != nil || got != want {
+			t.Errorf("SampleAt(%v) = %d, %v, want %d", u, got, err, want)
+		}
+	}
+
+	for _, u := range []float64{-0.1, 1, math.NaN()} {
+		if _, err := c.SampleAt(u); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("accepted u = %v", u)
+		}
+	}
+}
+
+// TestSampleAtFloat checks that quantiles map onto each
+// item's share of a floating-point distribution.
+func TestSampleAtFloat(t *testing.T) {
+	c, err := WeightedItemsFloat{{0.5, 0}, {1.5, 1}}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[float64]int{0: 0, 0.2: 0, 0.25: 1, 0.999: 1}
+	for u, want := range cases {
+		if got, err := c.SampleAt(u); err != nil || got != want {
+			t.Errorf("SampleAt(%v) = %d, %v, want %d", u, got, err, want)
+		}
+	}
+}
+
+// TestFloatMagnitudes checks that selection follows the weights
+// whether they're far smaller or far larger than EPSILON.
+func TestFloatMagnitudes(t *testing.T) {
+	for _, scale := range []float64{1e-9, 1e9} {
+		w := WeightedItemsFloat{{1 * scale, 0}, {2 * scale, 1}, {1 * scale, 2}}
+
+		c, err := w.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Sorted, the items cover [0, 0.25), [0.25, 0.5) and [0.5, 1)
+		cases := map[float64]int{0: 0, 0.2499: 0, 0.25: 2, 0.4999: 2, 0.5: 1, 0.999: 1}
+		for u, want := range cases {
+			if got, err := c.SampleAt(u); err != nil || got != want {
+				t.Errorf("scale %v: SampleAt(%v) = %d, %v, want %d", scale, u, got, err, want)
+			}
+		}
+
+		c.Reseed(9)
+		counts := SampleHistogram(c.Sample, 10000, 3)
+		if counts[0] < 2200 || counts[0] > 2800 || counts[1] < 4600 || counts[1] > 5400 {
+			t.Errorf("scale %v: got %v", scale, counts)
+		}
+	}
+}
+
+// TestSampleByDrawIndex checks that a draw number always gives the
+// same index for a seed, however many draws came before.
+func TestSampleByDrawIndex(t *testing.T) {
+	a, err := buildWeightedArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := buildWeightedArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Reseed(99)
+	b.Reseed(99)
+
+	// Advancing one CDF's generator mustn't matter
+	for i := 0; i < 10; i++ {
+		a.Sample()
+	}
+
+	counts := make([]int, 3)
+	for n := 999; n >= 0; n-- {
+		x, err := a.SampleByDrawIndex(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y, _ := b.SampleByDrawIndex(n); x != y {
+			t.Fatalf("draw %d: got %d and %d", n, x, y)
+		}
+		counts[x]++
+	}
+
+	// Index 2 holds 5 of the 8 units of weight
+	if counts[2] < 550 || counts[2] > 700 {
+		t.Errorf("got %v", counts)
+	}
+
+	if _, err := a.SampleByDrawIndex(-1); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want ErrOutOfRange", err)
+	}
+}
+
+// TestSeed checks that the seed reported matches
+// the one the generator was given.
+func TestSeed(t *testing.T) {
+	c, err := buildWeightedFloatArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Reseed(12)
+	if c.Seed() != 12 {
+		t.Errorf("got seed %d, want 12", c.Seed())
+	}
+
+	first, _ := c.SampleByDrawIndex(3)
+	c.Reseed(12)
+	if again, _ := c.SampleByDrawIndex(3); again != first {
+		t.Error("draw 3 changed for the same seed")
+	}
+}
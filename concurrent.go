@@ -0,0 +1,53 @@
+package stairs
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// lockedSource guards a rand.Source with a mutex
+// so it can be shared between goroutines.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+// newLockedSource creates a goroutine-safe source seeded from the current time.
+func newLockedSource() *lockedSource {
+	return &lockedSource{src: rand.NewSource(time.Now().UnixNano()).(rand.Source64)}
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// BuildConcurrentCDF works like BuildCDF, but the returned function
+// is safe to call from multiple goroutines at once.
+// Every call takes a lock around the random number generator, so
+// heavily contended callers may prefer one BuildCDF result per goroutine.
+func (s WeightedItems) BuildConcurrentCDF() (func() int, error) {
+	return s.BuildCDFWithRand(rand.New(newLockedSource()))
+}
+
+// BuildConcurrentCDF works like BuildCDF, but the returned function
+// is safe to call from multiple goroutines at once.
+// Every call takes a lock around the random number generator, so
+// heavily contended callers may prefer one BuildCDF result per goroutine.
+func (s WeightedItemsFloat) BuildConcurrentCDF() (func() int, error) {
+	return s.BuildCDFWithRand(rand.New(newLockedSource()))
+}
@@ -0,0 +1,58 @@
+package stairs
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestConcurrentCDF checks that the concurrent sampler can be
+// called from many goroutines at once. Run with -race to verify.
+func TestConcurrentCDF(t *testing.T) {
+	w := buildWeightedArray()
+
+	f, err := w.BuildConcurrentCDF()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if index := f(); index < 0 || index >= len(w) {
+					t.Error("index out of range")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestConcurrentCDFFloat checks that the concurrent floating-point
+// sampler can be called from many goroutines at once.
+func TestConcurrentCDFFloat(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	f, err := w.BuildConcurrentCDF()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if index := f(); index < 0 || index >= len(w) {
+					t.Error("index out of range")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
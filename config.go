@@ -0,0 +1,101 @@
+package stairs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+)
+
+// Config describes a sampler, so it can be defined in a configuration
+// file and built with NewFromConfig.
+type Config struct {
+	// Weights holds the weight of each index, in order
+	Weights []float64 `json:"weights"`
+	// Float selects the floating-point CDF. Otherwise every
+	// weight must be a whole number.
+	Float bool `json:"float"`
+	// Temperature, if set, tempers the weights as in BuildCDFTempered
+	Temperature *float64 `json:"temperature"`
+	// Epsilon, if set, smooths the weights as in BuildCDFSmoothed
+	Epsilon *float64 `json:"epsilon"`
+	// Seed, if set, makes the sampler's sequence reproducible
+	Seed *int64 `json:"seed"`
+}
+
+// NewFromConfig parses a JSON Config and builds the sampler it describes.
+// Tempering is applied before smoothing, and both need Float set. Unknown
+// fields and trailing data are rejected so typos don't go unnoticed, and
+// every error names the setting at fault while still matching the
+// package's errors with errors.Is.
+func NewFromConfig(jsonBytes []byte) (func() int, error) {
+	var c Config
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	// Reject anything after the config, which Decode leaves unread
+	if err := decoder.Decode(&json.RawMessage{}); err != io.EOF {
+		return nil, fmt.Errorf("config: unexpected data after the config: %w", ErrInvalidConfig)
+	}
+
+	r := newRand()
+	if c.Seed != nil {
+		r = rand.New(rand.NewSource(*c.Seed))
+	}
+
+	if !c.Float {
+		return c.buildInt(r)
+	}
+
+	w := FromFloatWeights(c.Weights)
+
+	var err error
+	if c.Temperature != nil {
+		if w, err = w.tempered(*c.Temperature); err != nil {
+			return nil, fmt.Errorf("temperature: %w", err)
+		}
+	}
+	if c.Epsilon != nil {
+		if w, err = w.smoothed(*c.Epsilon); err != nil {
+			return nil, fmt.Errorf("epsilon: %w", err)
+		}
+	}
+
+	f, err := w.BuildCDFWithRand(r)
+	if err != nil {
+		return nil, fmt.Errorf("weights: %w", err)
+	}
+	return f, nil
+}
+
+// buildInt builds an integer sampler from the config's weights.
+func (c Config) buildInt(r *rand.Rand) (func() int, error) {
+	if c.Temperature != nil || c.Epsilon != nil {
+		return nil, fmt.Errorf("temperature and epsilon need float weights: %w", ErrInvalidConfig)
+	}
+
+	w := make(WeightedItems, len(c.Weights))
+	for i, weight := range c.Weights {
+		if weight != math.Trunc(weight) {
+			return nil, fmt.Errorf("weights[%d] is %v, not a whole number: %w", i, weight, ErrInvalidConfig)
+		}
+
+		n, err := roundWeight(weight)
+		if err != nil {
+			return nil, fmt.Errorf("weights[%d]: %w", i, err)
+		}
+		w[i] = WeightedItem{n, i}
+	}
+
+	f, err := w.BuildCDFWithRand(r)
+	if err != nil {
+		return nil, fmt.Errorf("weights: %w", err)
+	}
+	return f, nil
+}
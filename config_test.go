@@ -0,0 +1,67 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestNewFromConfig checks that configs build samplers and
+// that a seed makes them reproducible.
+func TestNewFromConfig(t *testing.T) {
+	configs := []string{
+		`{"weights": [1, 2, 5], "seed": 4}`,
+		"{\"weights\": [1, 2, 5], \"seed\": 4}\n",
+		`{"weights": [1.5, 2.33, 5.8999], "float": true, "temperature": 2, "epsilon": 0.1, "seed": 4}`,
+	}
+
+	for _, config := range configs {
+		f, err := NewFromConfig([]byte(config))
+		if err != nil {
+			t.Fatalf("%s: %v", config, err)
+		}
+		g, err := NewFromConfig([]byte(config))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 100; i++ {
+			index := f()
+			if index < 0 || index > 2 || index != g() {
+				t.Fatalf("%s: got differing or invalid indices", config)
+			}
+		}
+	}
+}
+
+// TestNewFromConfigInvalid checks that bad configs
+// are rejected with the matching error.
+func TestNewFromConfigInvalid(t *testing.T) {
+	tests := []struct {
+		config string
+		want   error
+	}{
+		{`{"weights": []}`, ErrEmpty},
+		{`{"weights": [1, 0]}`, ErrNonPositiveWeight},
+		{`{"weights": [1.5]}`, ErrInvalidConfig},
+		{`{"weights": [1], "temperature": 2}`, ErrInvalidConfig},
+		{`{"weights": [1], "float": true, "temperature": -1}`, ErrOutOfRange},
+		{`{"weights": [1], "float": true, "temperature": 0}`, ErrOutOfRange},
+		{`{"weights": [1], "epsilon": 0}`, ErrInvalidConfig},
+		{`{"weights": [1], "float": true, "epsilon": 2}`, ErrOutOfRange},
+		{`{"weights": [1]} garbage`, ErrInvalidConfig},
+		{`{"weights": [1]} {"weights": [2]}`, ErrInvalidConfig},
+		{`{"weights": [1]} ]`, ErrInvalidConfig},
+	}
+
+	for _, test := range tests {
+		if _, err := NewFromConfig([]byte(test.config)); !errors.Is(err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.config, err, test.want)
+		}
+	}
+
+	for _, config := range []string{`{"weights": [1],`, `{"wieghts": [1]}`} {
+		if _, err := NewFromConfig([]byte(config)); err == nil {
+			t.Errorf("%s: accepted", config)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package stairs
+
+import (
+	"math"
+	"sort"
+)
+
+// QuantizeToInt converts floating-point weights to integer weights by
+// multiplying each by scale and rounding, so the faster integer CDF can
+// be used. Larger scales keep the proportions closer to the originals.
+// It fails if any weight rounds down to zero.
+func (s WeightedItemsFloat) QuantizeToInt(scale int) (WeightedItems, error) {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return nil, ErrEmpty
+	}
+
+	if scale <= 0 {
+		return nil, ErrNonPositiveScale
+	}
+
+	w := make(WeightedItems, len(s))
+	for i, item := range s {
+		weight, err := roundWeight(item.Weight * float64(scale))
+		if err != nil {
+			return nil, err
+		}
+		w[i] = WeightedItem{weight, item.Index}
+	}
+
+	return w, nil
+}
+
+// LostMass reports how far QuantizeToInt(scale) moves the distribution:
+// the sum, over every item, of the difference between its probability
+// under the float weights and under the rounded integer weights. It's 0
+// for an exact conversion and at most 2, so a small result means the
+// scale is fine enough.
+func (s WeightedItemsFloat) LostMass(scale int) (float64, error) {
+	q, err := s.QuantizeToInt(scale)
+	if err != nil {
+		return 0, err
+	}
+
+	total, err := s.total()
+	if err != nil {
+		return 0, err
+	}
+
+	qtotal, err := q.total()
+	if err != nil {
+		return 0, err
+	}
+
+	var lost kahanSum
+	for i, item := range s {
+		lost.add(math.Abs(item.Weight/total - float64(q[i].Weight)/float64(qtotal)))
+	}
+
+	return lost.sum, nil
+}
+
+// ScaleWeights multiplies every weight by factor and rounds the result,
+// keeping proportions within rounding error. Shrinking a distribution
+// keeps its total comfortably inside the int range; growing a small one
+// leaves room for finer adjustments. It fails if any weight rounds down to zero.
+func (s WeightedItems) ScaleWeights(factor float64) (WeightedItems, error) {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return nil, ErrEmpty
+	}
+
+	if !(factor > 0) || math.IsInf(factor, 1) {
+		return nil, ErrNonPositiveScale
+	}
+
+	w := make(WeightedItems, len(s))
+	for i, item := range s {
+		weight, err := roundWeight(float64(item.Weight) * factor)
+		if err != nil {
+			return nil, err
+		}
+		w[i] = WeightedItem{weight, item.Index}
+	}
+
+	return w, nil
+}
+
+// ToFloat returns a floating-point copy of the array, with the same
+// indices and weights, so float-only features like tempering apply.
+func (s WeightedItems) ToFloat() WeightedItemsFloat {
+	w := make(WeightedItemsFloat, len(s))
+	for i, item := range s {
+		w[i] = WeightedItemFloat{float64(item.Weight), item.Index}
+	}
+
+	return w
+}
+
+// ToInt returns an integer copy of the array, rounding each weight to the
+// nearest int and keeping its index. It fails if any weight rounds to
+// zero or less, or is too large for an int.
+func (s WeightedItemsFloat) ToInt() (WeightedItems, error) {
+	w := make(WeightedItems, len(s))
+	for i, item := range s {
+		weight, err := roundWeight(item.Weight)
+		if err != nil {
+			return nil, err
+		}
+		w[i] = WeightedItem{weight, item.Index}
+	}
+
+	return w, nil
+}
+
+// NormalizeTo returns a copy of the array rescaled so the weights sum to
+// exactly target, keeping their proportions as closely as whole numbers
+// allow. Each weight is scaled and rounded down, then the units still
+// needed to reach target go to the items that lost the most in rounding.
+// Every item keeps a weight of at least 1, taking units back from the
+// items that lost the least, so target must be at least the number of items.
+func (s WeightedItems) NormalizeTo(target int) (WeightedItems, error) {
+	total, err := s.total()
+	if err != nil {
+		return nil, err
+	}
+
+	if target < len(s) {
+		return nil, ErrOutOfRange
+	}
+
+	w := make(WeightedItems, len(s))
+	remainders := make([]float64, len(s))
+	sum := 0
+	for i, item := range s {
+		quota := float64(item.Weight) * float64(target) / float64(total)
+		weight := math.Floor(quota)
+
+		// Keep every item selectable
+		if weight < 1 {
+			weight = 1
+		}
+
+		w[i] = WeightedItem{int(weight), item.Index}
+		remainders[i] = quota - weight
+		sum += w[i].Weight
+	}
+
+	// Rank items from the largest rounding loss to the smallest
+	order := make([]int, len(s))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+
+	for i := 0; sum < target; i = (i + 1) % len(order) {
+		w[order[i]].Weight++
+		sum++
+	}
+
+	for i := len(order) - 1; sum > target; i = (i - 1 + len(order)) % len(order) {
+		if w[order[i]].Weight > 1 {
+			w[order[i]].Weight--
+			sum--
+		}
+	}
+
+	return w, nil
+}
+
+// roundWeight rounds a scaled weight to the nearest int,
+// making sure it stays positive and inside the int range.
+func roundWeight(scaled float64) (int, error) {
+	scaled = math.Round(scaled)
+
+	// Make sure the item still has positive weight
+	if !(scaled > 0) {
+		return 0, ErrNonPositiveWeight
+	}
+
+	// Make sure the weight fits in an int
+	if scaled >= math.MaxInt {
+		return 0, ErrWeightOverflow
+	}
+
+	return int(scaled), nil
+}
+
+// Coalesce returns a new array where items sharing an index are combined
+// into one item whose weight is their sum, in order of each index's first
+// appearance. BuildCDF rejects duplicate indices with ErrDuplicateIndex;
+// coalescing first is the way to opt into summing them instead.
+// A sum too large for an int is capped at math.MaxInt.
+func (s WeightedItems) Coalesce() WeightedItems {
+	out := make(WeightedItems, 0, len(s))
+	pos := make(map[int]int, len(s))
+
+	for _, item := range s {
+		i, ok := pos[item.Index]
+		if !ok {
+			pos[item.Index] = len(out)
+			out = append(out, item)
+			continue
+		}
+
+		if item.Weight > 0 && out[i].Weight > math.MaxInt-item.Weight {
+			out[i].Weight = math.MaxInt
+		} else {
+			out[i].Weight += item.Weight
+		}
+	}
+
+	return out
+}
+
+// Coalesce returns a new array where items sharing an index are combined
+// into one item whose weight is their sum, in order of each index's first
+// appearance. BuildCDF rejects duplicate indices with ErrDuplicateIndex;
+// coalescing first is the way to opt into summing them instead.
+func (s WeightedItemsFloat) Coalesce() WeightedItemsFloat {
+	out := make(WeightedItemsFloat, 0, len(s))
+	pos := make(map[int]int, len(s))
+
+	for _, item := range s {
+		if i, ok := pos[item.Index]; ok {
+			out[i].Weight += item.Weight
+			continue
+		}
+		pos[item.Index] = len(out)
+		out = append(out, item)
+	}
+
+	return out
+}
@@ -0,0 +1,217 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestQuantizeToInt checks that float weights are
+// scaled and rounded into integer weights.
+func TestQuantizeToInt(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	q, err := w.QuantizeToInt(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := WeightedItems{{150, 0}, {233, 1}, {590, 2}}
+	for i := range want {
+		if q[i] != want[i] {
+			t.Errorf("got %v, want %v", q, want)
+		}
+	}
+
+	if _, err := q.BuildCDF(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestQuantizeToIntRoundsToZero checks that a weight
+// too small for the scale is rejected.
+func TestQuantizeToIntRoundsToZero(t *testing.T) {
+	w := WeightedItemsFloat{{0.001, 0}, {2, 1}}
+
+	if _, err := w.QuantizeToInt(10); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Fail()
+	}
+}
+
+// TestQuantizeToIntBadScale checks that a non-positive scale is rejected.
+func TestQuantizeToIntBadScale(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	if _, err := w.QuantizeToInt(0); !errors.Is(err, ErrNonPositiveScale) {
+		t.Fail()
+	}
+}
+
+// TestScaleWeights checks that integer weights are
+// scaled up and down with rounding.
+func TestScaleWeights(t *testing.T) {
+	w := WeightedItems{{1000, 0}, {2550, 1}, {60, 2}}
+
+	down, err := w.ScaleWeights(0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := WeightedItems{{10, 0}, {26, 1}, {1, 2}}
+	for i := range want {
+		if down[i] != want[i] {
+			t.Errorf("got %v, want %v", down, want)
+		}
+	}
+
+	up, err := w.ScaleWeights(3)
+	if err != nil || up[1] != (WeightedItem{7650, 1}) {
+		t.Errorf("got %v, %v", up, err)
+	}
+}
+
+// TestScaleWeightsToZero checks that scaling a weight
+// down to zero is rejected.
+func TestScaleWeightsToZero(t *testing.T) {
+	w := WeightedItems{{1000, 0}, {10, 1}}
+
+	if _, err := w.ScaleWeights(0.01); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Error("scaled a weight to zero")
+	}
+
+	if _, err := w.ScaleWeights(-2); !errors.Is(err, ErrNonPositiveScale) {
+		t.Error("accepted a negative factor")
+	}
+}
+
+// TestCoalesce checks that duplicate indices are summed
+// in order of first appearance.
+func TestCoalesce(t *testing.T) {
+	w := WeightedItems{{1, 4}, {2, 0}, {3, 4}, {4, 1}, {5, 0}}
+
+	c := w.Coalesce()
+
+	want := WeightedItems{{4, 4}, {7, 0}, {4, 1}}
+	if len(c) != len(want) {
+		t.Fatalf("got %v, want %v", c, want)
+	}
+	for i := range want {
+		if c[i] != want[i] {
+			t.Errorf("got %v, want %v", c, want)
+		}
+	}
+
+	if _, err := c.BuildCDF(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestCoalesceFloat checks that duplicate floating-point
+// indices are summed.
+func TestCoalesceFloat(t *testing.T) {
+	c := WeightedItemsFloat{{0.5, 1}, {0.25, 1}}.Coalesce()
+
+	if len(c) != 1 || c[0] != (WeightedItemFloat{0.75, 1}) {
+		t.Errorf("got %v", c)
+	}
+}
+
+// TestToFloatToInt checks that converting between the two
+// representations keeps indices and weights.
+func TestToFloatToInt(t *testing.T) {
+	w := buildWeightedArray()
+
+	f := w.ToFloat()
+	for i, item := range f {
+		if item.Index != w[i].Index || item.Weight != float64(w[i].Weight) {
+			t.Errorf("got %v, want %v", item, w[i])
+		}
+	}
+
+	back, err := f.ToInt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, item := range back {
+		if item != w[i] {
+			t.Errorf("got %v, want %v", item, w[i])
+		}
+	}
+
+	if w, err := (WeightedItemsFloat{{2.6, 4}}).ToInt(); err != nil || w[0] != (WeightedItem{3, 4}) {
+		t.Errorf("got %v, %v; want a rounded weight of 3", w, err)
+	}
+}
+
+// TestToIntUnrepresentable checks that weights that can't
+// become positive ints are rejected.
+func TestToIntUnrepresentable(t *testing.T) {
+	if _, err := (WeightedItemsFloat{{0.2, 0}}).ToInt(); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Errorf("got %v, want ErrNonPositiveWeight", err)
+	}
+
+	if _, err := (WeightedItemsFloat{{1e300, 0}}).ToInt(); !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("got %v, want ErrWeightOverflow", err)
+	}
+}
+
+// TestNormalizeTo checks that the weights sum exactly to the target,
+// stay proportional and never drop to zero.
+func TestNormalizeTo(t *testing.T) {
+	w := WeightedItems{{1, 0}, {1, 1}, {1, 2}}
+
+	n, err := w.NormalizeTo(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 1000 doesn't divide by 3, so one item gets the spare unit
+	want := WeightedItems{{334, 0}, {333, 1}, {333, 2}}
+	for i := range want {
+		if n[i] != want[i] {
+			t.Errorf("got %v, want %v", n, want)
+		}
+	}
+
+	n, err = WeightedItems{{1, 0}, {1000000, 1}, {1, 2}}.NormalizeTo(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = WeightedItems{{1, 0}, {8, 1}, {1, 2}}
+	for i := range want {
+		if n[i] != want[i] {
+			t.Errorf("got %v, want %v", n, want)
+		}
+	}
+
+	if _, err := w.NormalizeTo(2); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want ErrOutOfRange", err)
+	}
+}
+
+// TestLostMass checks that exact conversions lose nothing
+// and finer scales lose less.
+func TestLostMass(t *testing.T) {
+	exact, err := WeightedItemsFloat{{0.5, 0}, {1.5, 1}}.LostMass(2)
+	if err != nil || exact != 0 {
+		t.Errorf("got %v, %v; want no loss", exact, err)
+	}
+
+	w := WeightedItemsFloat{{1, 0}, {1, 1}, {1.4, 2}}
+
+	coarse, err := w.LostMass(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fine, err := w.LostMass(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !(fine < coarse) || coarse <= 0 {
+		t.Errorf("got %v at scale 1 and %v at scale 1000", coarse, fine)
+	}
+
+	if _, err := w.LostMass(0); !errors.Is(err, ErrNonPositiveScale) {
+		t.Errorf("got %v, want ErrNonPositiveScale", err)
+	}
+}
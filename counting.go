@@ -0,0 +1,57 @@
+package stairs
+
+// CountingSampler selects random indices like the function returned by
+// BuildCDF, and also counts how many times each index has been returned,
+// so the observed distribution can be checked against the weights.
+//
+// A CountingSampler is not safe for concurrent use.
+type CountingSampler struct {
+	sample func() int
+	counts map[int]int
+}
+
+// BuildCounting works like BuildCDF, but returns a CountingSampler.
+// Use BuildCDF instead when the counts aren't needed, to skip the
+// cost of updating them on every draw.
+func (s WeightedItems) BuildCounting() (*CountingSampler, error) {
+	f, err := s.BuildCDF()
+	if err != nil {
+		return nil, err
+	}
+	return newCountingSampler(f, len(s)), nil
+}
+
+// BuildCounting works like BuildCDF, but returns a CountingSampler.
+// Use BuildCDF instead when the counts aren't needed, to skip the
+// cost of updating them on every draw.
+func (s WeightedItemsFloat) BuildCounting() (*CountingSampler, error) {
+	f, err := s.BuildCDF()
+	if err != nil {
+		return nil, err
+	}
+	return newCountingSampler(f, len(s)), nil
+}
+
+// newCountingSampler wraps f, sizing the counts for n distinct indices.
+func newCountingSampler(f func() int, n int) *CountingSampler {
+	return &CountingSampler{sample: f, counts: make(map[int]int, n)}
+}
+
+// Sample returns the original index of a random item,
+// chosen according to the weights, and counts it.
+func (c *CountingSampler) Sample() int {
+	index := c.sample()
+	c.counts[index]++
+	return index
+}
+
+// Stats returns how many times each index has been returned by Sample.
+// Indices that haven't been returned yet are absent. The map is a copy,
+// so it's safe to keep while sampling continues.
+func (c *CountingSampler) Stats() map[int]int {
+	out := make(map[int]int, len(c.counts))
+	for index, n := range c.counts {
+		out[index] = n
+	}
+	return out
+}
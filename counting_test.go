@@ -0,0 +1,51 @@
+package stairs
+
+import "testing"
+
+// TestCountingSampler checks that the counts match
+// the indices returned by Sample.
+func TestCountingSampler(t *testing.T) {
+	c, err := buildWeightedArray().BuildCounting()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		want[c.Sample()]++
+	}
+
+	stats := c.Stats()
+	if len(stats) != len(want) {
+		t.Fatalf("got %v, want %v", stats, want)
+	}
+	for index, n := range want {
+		if stats[index] != n {
+			t.Errorf("got %v, want %v", stats, want)
+		}
+	}
+
+	// Changing the copy mustn't affect later counts
+	stats[0] = -1
+	if c.Stats()[0] == -1 {
+		t.Error("Stats returned the internal map")
+	}
+}
+
+// TestCountingSamplerFloat checks that nothing is
+// counted before the first draw.
+func TestCountingSamplerFloat(t *testing.T) {
+	c, err := buildWeightedFloatArray().BuildCounting()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Stats()) != 0 {
+		t.Errorf("got %v before sampling", c.Stats())
+	}
+
+	c.Sample()
+	if len(c.Stats()) != 1 {
+		t.Errorf("got %v after one draw", c.Stats())
+	}
+}
@@ -0,0 +1,60 @@
+package stairs
+
+import (
+	crand "crypto/rand"
+	"math/big"
+)
+
+// BuildCDFCrypto works like BuildCDF, but the returned function draws its
+// random numbers from crypto/rand instead of math/rand, so selections are
+// unpredictable enough for security-sensitive uses. This is much slower
+// than BuildCDF. The function returns an error if the system's entropy
+// source can't be read.
+func (s WeightedItems) BuildCDFCrypto() (func() (int, error), error) {
+	c, err := s.build(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	max := big.NewInt(int64(c.total()))
+
+	searchCDF := func() (int, error) {
+		// Picking a random number in the range [1, max weight + 1)
+		n, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return 0, err
+		}
+		num := int(n.Int64()) + 1
+
+		return c.items[c.search(num)].Index, nil
+	}
+	return searchCDF, nil
+}
+
+// float53 is the number of distinct float64 values in [0, 1)
+// that can be produced with full precision.
+var float53 = big.NewInt(1 << 53)
+
+// BuildCDFCrypto works like BuildCDF, but the returned function draws its
+// random numbers from crypto/rand instead of math/rand, so selections are
+// unpredictable enough for security-sensitive uses. This is much slower
+// than BuildCDF. The function returns an error if the system's entropy
+// source can't be read.
+func (s WeightedItemsFloat) BuildCDFCrypto() (func() (int, error), error) {
+	c, err := s.build(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	searchCDF := func() (int, error) {
+		// Picking a random number in the range [0, max weight)
+		n, err := crand.Int(crand.Reader, float53)
+		if err != nil {
+			return 0, err
+		}
+		num := float64(n.Int64()) / (1 << 53) * c.total()
+
+		return c.items[c.search(num)].Index, nil
+	}
+	return searchCDF, nil
+}
@@ -0,0 +1,52 @@
+package stairs
+
+import (
+	"math"
+	"testing"
+)
+
+// TestBuildCDFCrypto checks that the crypto sampler
+// follows the weights.
+func TestBuildCDFCrypto(t *testing.T) {
+	w := buildWeightedArray()
+
+	f, err := w.BuildCDFCrypto()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const draws = 20000
+	counts := make([]int, len(w))
+	for i := 0; i < draws; i++ {
+		index, err := f()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[index]++
+	}
+
+	if observed := float64(counts[2]) / draws; math.Abs(observed-0.625) > 0.02 {
+		t.Errorf("heaviest item observed %v of the time, want 0.625", observed)
+	}
+}
+
+// TestBuildCDFCryptoFloat checks that the floating-point
+// crypto sampler only returns valid indices.
+func TestBuildCDFCryptoFloat(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	f, err := w.BuildCDFCrypto()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		index, err := f()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index < 0 || index >= len(w) {
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package stairs
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadCSV reads label,weight rows from r. The first row is treated as a
+// header and skipped. Each item's Index is its row number, not counting
+// the header, and labels[i] holds the label for index i. Malformed rows
+// produce an error naming the line they're on. Weights are checked
+// when a CDF is built, not here.
+func LoadCSV(r io.Reader) (WeightedItemsFloat, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+
+	// Skip the header
+	if _, err := reader.Read(); err == io.EOF {
+		return WeightedItemsFloat{}, []string{}, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	w := WeightedItemsFloat{}
+	labels := []string{}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		weight, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			line, _ := reader.FieldPos(1)
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		w = append(w, WeightedItemFloat{weight, len(w)})
+		labels = append(labels, record[0])
+	}
+
+	return w, labels, nil
+}
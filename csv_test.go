@@ -0,0 +1,44 @@
+package stairs
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLoadCSV checks that rows after the header become
+// weighted items with matching labels.
+func TestLoadCSV(t *testing.T) {
+	w, labels, err := LoadCSV(strings.NewReader("label,weight\nstr,1.5\nstr2, 2.33\nstr3,5.8999\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := buildWeightedFloatArray()
+	if len(w) != len(want) || len(labels) != len(want) {
+		t.Fatalf("got %v and %v", w, labels)
+	}
+
+	for i := range want {
+		if w[i] != want[i] {
+			t.Errorf("got %v, want %v", w, want)
+		}
+	}
+
+	if labels[0] != "str" || labels[1] != "str2" || labels[2] != "str3" {
+		t.Errorf("got labels %v", labels)
+	}
+}
+
+// TestLoadCSVMalformed checks that a bad weight or a short row
+// is reported along with its line number.
+func TestLoadCSVMalformed(t *testing.T) {
+	_, _, err := LoadCSV(strings.NewReader("label,weight\na,1\nb,heavy\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("got %v, want an error on line 3", err)
+	}
+
+	_, _, err = LoadCSV(strings.NewReader("label,weight\na,1\nb\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("got %v, want an error on line 3", err)
+	}
+}
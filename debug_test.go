@@ -0,0 +1,22 @@
+//go:build stairsdebug
+
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestPresortedUnsorted checks that debug builds reject
+// arrays passed as presorted that aren't in order.
+func TestPresortedUnsorted(t *testing.T) {
+	w := WeightedItems{{5, 0}, {1, 1}}
+	if _, err := w.BuildCDFPresorted(); !errors.Is(err, ErrNotSorted) {
+		t.Error("accepted an unsorted array")
+	}
+
+	f := WeightedItemsFloat{{5.5, 0}, {1.5, 1}}
+	if _, err := f.BuildCDFPresorted(); !errors.Is(err, ErrNotSorted) {
+		t.Error("accepted an unsorted floating-point array")
+	}
+}
@@ -0,0 +1,55 @@
+package stairs
+
+import (
+	"math"
+	"time"
+)
+
+// TimedWeightedItem contains the floating-point weight for the item,
+// the index it represents in the original array and when it was added.
+type TimedWeightedItem struct {
+	// The relative weight assigned to the item when it was added
+	Weight float64 `json:"weight"`
+	// Index is the location in the original array
+	// for the item
+	Index int `json:"index"`
+	// Added is when the item was added, which its age is measured from
+	Added time.Time `json:"added"`
+}
+
+// TimedWeightedItems is an array of TimedWeightedItem items.
+type TimedWeightedItems []TimedWeightedItem
+
+// BuildCDFDecayed works like BuildCDF, but first halves each item's
+// weight for every halfLife that has passed between its Added time and
+// now, so older items are proportionally less likely to be selected.
+// Items added after now aren't boosted; they keep their full weight.
+// halfLife must be positive. An item old enough for its weight to
+// decay to zero is rejected with ErrNonPositiveWeight.
+func (s TimedWeightedItems) BuildCDFDecayed(halfLife time.Duration, now time.Time) (func() int, error) {
+	w, err := s.decayed(halfLife, now)
+	if err != nil {
+		return nil, err
+	}
+	return w.BuildCDF()
+}
+
+// decayed returns the items' weights after exponential decay up to now.
+func (s TimedWeightedItems) decayed(halfLife time.Duration, now time.Time) (WeightedItemsFloat, error) {
+	if halfLife <= 0 {
+		return nil, ErrOutOfRange
+	}
+
+	w := make(WeightedItemsFloat, len(s))
+	for i, item := range s {
+		age := now.Sub(item.Added)
+		if age < 0 {
+			age = 0
+		}
+
+		decay := math.Exp2(-float64(age) / float64(halfLife))
+		w[i] = WeightedItemFloat{item.Weight * decay, item.Index}
+	}
+
+	return w, nil
+}
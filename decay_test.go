@@ -0,0 +1,41 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestDecayed checks that weights halve every half-life
+// and that future items keep their full weight.
+func TestDecayed(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	s := TimedWeightedItems{
+		{8, 0, now},
+		{8, 1, now.Add(-day)},
+		{8, 2, now.Add(-3 * day)},
+		{8, 3, now.Add(day)},
+	}
+
+	w, err := s.decayed(day, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float64{8, 4, 1, 8}
+	for i := range want {
+		if w[i].Weight != want[i] || w[i].Index != i {
+			t.Errorf("got %v, want weights %v", w, want)
+		}
+	}
+
+	if _, err := s.BuildCDFDecayed(day, now); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := s.BuildCDFDecayed(0, now); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want ErrOutOfRange", err)
+	}
+}
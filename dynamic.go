@@ -0,0 +1,150 @@
+package stairs
+
+import (
+	"math"
+	"math/rand"
+)
+
+// DynamicSampler samples from a weighted set of items whose weights
+// can change over time. Adding, removing and updating an item and
+// drawing a sample each take O(log n), with no rebuild in between.
+//
+// The zero value is ready to use and seeds its own generator.
+type DynamicSampler struct {
+	tree fenwick
+	// weights and indices hold the current weight and original
+	// index for each position in the tree
+	weights []int
+	indices []int
+	// slots maps original indices to positions in the tree
+	slots map[int]int
+	// free holds positions left behind by removed items
+	free  []int
+	total int
+	r     *rand.Rand
+}
+
+// NewDynamicSampler creates an empty DynamicSampler that draws from r.
+// If r is nil, a generator seeded from the current time is used.
+func NewDynamicSampler(r *rand.Rand) *DynamicSampler {
+	return &DynamicSampler{r: r}
+}
+
+// AddItem adds an item with the given weight and original index.
+func (d *DynamicSampler) AddItem(weight, index int) error {
+	// Make sure all items have positive weight
+	if weight <= 0 {
+		return ErrNonPositiveWeight
+	}
+
+	if _, ok := d.slots[index]; ok {
+		return ErrDuplicateIndex
+	}
+
+	// Make sure the running total can't wrap around
+	if weight > math.MaxInt-d.total {
+		return ErrWeightOverflow
+	}
+
+	if d.slots == nil {
+		d.slots = make(map[int]int)
+	}
+
+	// Reuse a removed item's position if there is one
+	var pos int
+	if n := len(d.free); n > 0 {
+		pos = d.free[n-1]
+		d.free = d.free[:n-1]
+		d.indices[pos] = index
+	} else {
+		pos = d.tree.grow()
+		d.weights = append(d.weights, 0)
+		d.indices = append(d.indices, index)
+	}
+
+	d.slots[index] = pos
+	d.set(pos, weight)
+
+	return nil
+}
+
+// RemoveItem removes the item with the given original index.
+func (d *DynamicSampler) RemoveItem(index int) error {
+	pos, ok := d.slots[index]
+	if !ok {
+		return ErrIndexNotFound
+	}
+
+	d.set(pos, 0)
+	delete(d.slots, index)
+	d.free = append(d.free, pos)
+
+	return nil
+}
+
+// UpdateWeight changes the weight of the item with the given original index.
+func (d *DynamicSampler) UpdateWeight(index, newWeight int) error {
+	// Make sure all items have positive weight
+	if newWeight <= 0 {
+		return ErrNonPositiveWeight
+	}
+
+	pos, ok := d.slots[index]
+	if !ok {
+		return ErrIndexNotFound
+	}
+
+	// Make sure the running total can't wrap around
+	if newWeight-d.weights[pos] > math.MaxInt-d.total {
+		return ErrWeightOverflow
+	}
+
+	d.set(pos, newWeight)
+
+	return nil
+}
+
+// Weight returns the current weight of the item with the given original index.
+func (d *DynamicSampler) Weight(index int) (int, error) {
+	pos, ok := d.slots[index]
+	if !ok {
+		return 0, ErrIndexNotFound
+	}
+	return d.weights[pos], nil
+}
+
+// Len returns the number of items in the sampler.
+func (d *DynamicSampler) Len() int {
+	return len(d.slots)
+}
+
+// Total returns the sum of the current weights.
+func (d *DynamicSampler) Total() int {
+	return d.total
+}
+
+// Sample returns the original index of a random item,
+// chosen according to the current weights.
+func (d *DynamicSampler) Sample() (int, error) {
+	// Nothing to pick from
+	if d.total <= 0 {
+		return 0, ErrEmpty
+	}
+
+	if d.r == nil {
+		d.r = newRand()
+	}
+
+	// Picking a random number in the range [1, total weight + 1)
+	num := d.r.Intn(d.total) + 1
+
+	return d.indices[d.tree.search(num)], nil
+}
+
+// set changes the weight stored at a position in the tree.
+func (d *DynamicSampler) set(pos, weight int) {
+	delta := weight - d.weights[pos]
+	d.tree.add(pos, delta)
+	d.weights[pos] = weight
+	d.total += delta
+}
@@ -0,0 +1,101 @@
+package stairs
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// TestDynamicSampler checks that samples follow
+// the weights as items are added, updated and removed.
+func TestDynamicSampler(t *testing.T) {
+	d := NewDynamicSampler(rand.New(rand.NewSource(1)))
+
+	for i := 0; i < 5; i++ {
+		if err := d.AddItem(1, i*10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Make the last item dominate the others
+	if err := d.UpdateWeight(40, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	for i := 0; i < 1000; i++ {
+		index, err := d.Sample()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index == 40 {
+			count++
+		}
+	}
+
+	if count < 950 {
+		t.Errorf("heavy item drawn %d times out of 1000", count)
+	}
+
+	// Once removed, the item must never come up again
+	if err := d.RemoveItem(40); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		index, _ := d.Sample()
+		if index == 40 || index%10 != 0 || index < 0 || index > 30 {
+			t.Fatalf("got removed or unknown index %d", index)
+		}
+	}
+
+	if d.Len() != 4 || d.Total() != 4 {
+		t.Errorf("got len %d and total %d", d.Len(), d.Total())
+	}
+}
+
+// TestDynamicSamplerReuse checks that a removed
+// item's position is reused by the next one added.
+func TestDynamicSamplerReuse(t *testing.T) {
+	var d DynamicSampler
+
+	d.AddItem(3, 0)
+	d.AddItem(4, 1)
+	d.RemoveItem(0)
+	d.AddItem(5, 2)
+
+	if d.tree.Len() != 2 || d.Total() != 9 {
+		t.Errorf("got %d positions and total %d", d.tree.Len(), d.Total())
+	}
+
+	if w, err := d.Weight(2); err != nil || w != 5 {
+		t.Errorf("got weight %d, %v", w, err)
+	}
+}
+
+// TestDynamicSamplerErrors checks that invalid changes are rejected.
+func TestDynamicSamplerErrors(t *testing.T) {
+	var d DynamicSampler
+
+	if _, err := d.Sample(); !errors.Is(err, ErrEmpty) {
+		t.Error("sampled an empty sampler")
+	}
+
+	if err := d.AddItem(0, 0); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Error("added a zero weight")
+	}
+
+	d.AddItem(1, 0)
+
+	if err := d.AddItem(1, 0); !errors.Is(err, ErrDuplicateIndex) {
+		t.Error("added a duplicate index")
+	}
+
+	if err := d.UpdateWeight(5, 1); !errors.Is(err, ErrIndexNotFound) {
+		t.Error("updated a missing index")
+	}
+
+	if err := d.RemoveItem(5); !errors.Is(err, ErrIndexNotFound) {
+		t.Error("removed a missing index")
+	}
+}
@@ -0,0 +1,113 @@
+package stairs
+
+import "math/rand"
+
+// ExclusionSampler samples from a fixed set of items, any of which can be
+// excluded from selection and later included again without rebuilding.
+// Draws follow the weights of the included items only. Sampling and
+// each exclusion or inclusion take O(log n).
+//
+// An ExclusionSampler is not safe for concurrent use.
+type ExclusionSampler struct {
+	tree fenwick
+	// weights, indices and excluded describe each position in the tree
+	weights  []int
+	indices  []int
+	excluded []bool
+	// slots maps original indices to positions in the tree
+	slots map[int]int
+	total int
+	r     *rand.Rand
+}
+
+// NewExclusionSampler creates an ExclusionSampler with every item
+// included. If r is nil, a generator seeded from the current time is used.
+func NewExclusionSampler(items WeightedItems, r *rand.Rand) (*ExclusionSampler, error) {
+	if err := items.Validate(); err != nil {
+		return nil, err
+	}
+
+	total, err := items.total()
+	if err != nil {
+		return nil, err
+	}
+
+	if r == nil {
+		r = newRand()
+	}
+
+	e := &ExclusionSampler{
+		weights:  make([]int, len(items)),
+		indices:  make([]int, len(items)),
+		excluded: make([]bool, len(items)),
+		slots:    make(map[int]int, len(items)),
+		total:    total,
+		r:        r,
+	}
+
+	for i, item := range items {
+		e.tree.add(e.tree.grow(), item.Weight)
+		e.weights[i] = item.Weight
+		e.indices[i] = item.Index
+		e.slots[item.Index] = i
+	}
+
+	return e, nil
+}
+
+// Exclude stops the items with the given original indices from being
+// selected. Excluding an item twice has no further effect. If any index
+// isn't in the sampler, it returns ErrIndexNotFound and changes nothing.
+func (e *ExclusionSampler) Exclude(indices ...int) error {
+	return e.setExcluded(indices, true)
+}
+
+// Include lets excluded items with the given original indices be selected
+// again, with their original weights. Including an item that isn't
+// excluded has no effect. If any index isn't in the sampler, it returns
+// ErrIndexNotFound and changes nothing.
+func (e *ExclusionSampler) Include(indices ...int) error {
+	return e.setExcluded(indices, false)
+}
+
+// setExcluded marks every item in indices as excluded or included.
+func (e *ExclusionSampler) setExcluded(indices []int, excluded bool) error {
+	// Check every index first so a bad one leaves nothing half done
+	for _, index := range indices {
+		if _, ok := e.slots[index]; !ok {
+			return ErrIndexNotFound
+		}
+	}
+
+	for _, index := range indices {
+		pos := e.slots[index]
+		if e.excluded[pos] == excluded {
+			continue
+		}
+
+		delta := e.weights[pos]
+		if excluded {
+			delta = -delta
+		}
+
+		e.tree.add(pos, delta)
+		e.total += delta
+		e.excluded[pos] = excluded
+	}
+
+	return nil
+}
+
+// Sample returns the original index of a random included item, chosen
+// according to the weights. It returns ErrEmpty if every item is excluded.
+func (e *ExclusionSampler) Sample() (int, error) {
+	// Nothing to pick from
+	if e.total <= 0 {
+		return 0, ErrEmpty
+	}
+
+	// Picking a random number in the range [1, total weight + 1)
+	num := e.r.Intn(e.total) + 1
+
+	return e.indices[e.tree.search(num)], nil
+}
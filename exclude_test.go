@@ -0,0 +1,65 @@
+package stairs
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// TestExclusionSampler checks that excluded items are never
+// selected until they're included again.
+func TestExclusionSampler(t *testing.T) {
+	e, err := NewExclusionSampler(buildWeightedArray(), rand.New(rand.NewSource(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Exclude(2, 2); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if index, err := e.Sample(); err != nil || index == 2 {
+			t.Fatalf("got %d, %v", index, err)
+		}
+	}
+
+	if err := e.Include(2); err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[int]int)
+	for i := 0; i < 8000; i++ {
+		index, err := e.Sample()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[index]++
+	}
+
+	// Index 2 is back with 5 of the 8 units of weight
+	if counts[2] < 4600 || counts[2] > 5400 {
+		t.Errorf("got %v", counts)
+	}
+}
+
+// TestExclusionSamplerAll checks that excluding everything
+// is an error, and that unknown indices change nothing.
+func TestExclusionSamplerAll(t *testing.T) {
+	e, err := NewExclusionSampler(buildWeightedArray(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Exclude(0, 9); !errors.Is(err, ErrIndexNotFound) {
+		t.Errorf("got %v, want ErrIndexNotFound", err)
+	}
+	if e.total != 8 {
+		t.Errorf("got total %d after a failed exclusion, want 8", e.total)
+	}
+
+	if err := e.Exclude(0, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Sample(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+}
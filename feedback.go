@@ -0,0 +1,75 @@
+package stairs
+
+import "math/rand"
+
+// FeedbackSampler is a DynamicSampler whose weights adjust themselves
+// after every draw, such as boosting or decaying the selected item for
+// bandit-style exploration. Each draw and update takes O(log n).
+//
+// A FeedbackSampler is not safe for concurrent use.
+type FeedbackSampler struct {
+	d        *DynamicSampler
+	onSelect func(index, currentWeight int) int
+}
+
+// NewFeedbackSampler creates a FeedbackSampler over the items. After each
+// draw, onSelect is called with the selected index and its current weight,
+// and returns the item's new weight; a weight of zero or less removes the
+// item. If onSelect is nil, weights never change. If r is nil, a
+// generator seeded from the current time is used.
+func NewFeedbackSampler(items WeightedItems, onSelect func(index, currentWeight int) int, r *rand.Rand) (*FeedbackSampler, error) {
+	if err := items.Validate(); err != nil {
+		return nil, err
+	}
+
+	d := NewDynamicSampler(r)
+	for _, item := range items {
+		if err := d.AddItem(item.Weight, item.Index); err != nil {
+			return nil, err
+		}
+	}
+
+	return &FeedbackSampler{d: d, onSelect: onSelect}, nil
+}
+
+// Sample returns the original index of a random item, chosen according
+// to the current weights, then applies onSelect to that item's weight.
+// It returns ErrEmpty once every item has been removed, and
+// ErrWeightOverflow, leaving the weight unchanged, if the new weight
+// would push the total out of the int range.
+func (f *FeedbackSampler) Sample() (int, error) {
+	index, err := f.d.Sample()
+	if err != nil {
+		return 0, err
+	}
+
+	if f.onSelect == nil {
+		return index, nil
+	}
+
+	weight, err := f.d.Weight(index)
+	if err != nil {
+		return 0, err
+	}
+
+	if weight = f.onSelect(index, weight); weight <= 0 {
+		err = f.d.RemoveItem(index)
+	} else {
+		err = f.d.UpdateWeight(index, weight)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return index, nil
+}
+
+// Weight returns the current weight of the item with the given original index.
+func (f *FeedbackSampler) Weight(index int) (int, error) {
+	return f.d.Weight(index)
+}
+
+// Len returns the number of items that can still be selected.
+func (f *FeedbackSampler) Len() int {
+	return f.d.Len()
+}
@@ -0,0 +1,71 @@
+package stairs
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// TestFeedbackSampler checks that the selected item's
+// weight is updated after each draw.
+func TestFeedbackSampler(t *testing.T) {
+	boost := func(index, weight int) int { return weight + 1 }
+
+	f, err := NewFeedbackSampler(buildWeightedArray(), boost, rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]int{0: 1, 1: 2, 2: 5}
+	for i := 0; i < 100; i++ {
+		index, err := f.Sample()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[index]++
+	}
+
+	for index, weight := range want {
+		if got, err := f.Weight(index); err != nil || got != weight {
+			t.Errorf("index %d: got %d, %v, want %d", index, got, err, weight)
+		}
+	}
+}
+
+// TestFeedbackSamplerRemove checks that items whose weight drops to
+// zero are removed, and that a nil update leaves weights alone.
+func TestFeedbackSamplerRemove(t *testing.T) {
+	once := func(index, weight int) int { return 0 }
+
+	f, err := NewFeedbackSampler(buildWeightedArray(), once, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[int]bool)
+	for i := 0; i < 3; i++ {
+		index, err := f.Sample()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[index] {
+			t.Fatalf("index %d came up after removal", index)
+		}
+		seen[index] = true
+	}
+
+	if _, err := f.Sample(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+
+	f, err = NewFeedbackSampler(buildWeightedArray(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		f.Sample()
+	}
+	if weight, _ := f.Weight(2); weight != 5 {
+		t.Errorf("got weight %d, want 5", weight)
+	}
+}
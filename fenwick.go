@@ -0,0 +1,70 @@
+package stairs
+
+// fenwick is a binary indexed tree over integer weights.
+// It supports point updates, prefix sums and searching
+// for a cumulative weight in O(log n).
+type fenwick struct {
+	// tree is 1-based; tree[0] is unused
+	tree []int
+}
+
+// Len returns the number of positions in the tree.
+func (f *fenwick) Len() int {
+	if len(f.tree) == 0 {
+		return 0
+	}
+	return len(f.tree) - 1
+}
+
+// grow appends a new zero-weight position and returns it.
+func (f *fenwick) grow() int {
+	if len(f.tree) == 0 {
+		f.tree = append(f.tree, 0)
+	}
+
+	// The new node covers (i - lowbit(i), i], all of which is already stored
+	// except the new position itself, which starts at zero.
+	i := len(f.tree)
+	f.tree = append(f.tree, f.prefix(i-1)-f.prefix(i-(i&-i)))
+
+	return i - 1
+}
+
+// add adds delta to the weight at position pos.
+func (f *fenwick) add(pos, delta int) {
+	for i := pos + 1; i < len(f.tree); i += i & -i {
+		f.tree[i] += delta
+	}
+}
+
+// prefix returns the sum of the weights at positions [0, n).
+func (f *fenwick) prefix(n int) int {
+	sum := 0
+	for i := n; i > 0; i -= i & -i {
+		sum += f.tree[i]
+	}
+	return sum
+}
+
+// search returns the first position whose cumulative weight is at
+// least num. num must be in the range [1, total weight].
+func (f *fenwick) search(num int) int {
+	n := f.Len()
+
+	// Find the largest power of two that fits in the tree
+	step := 1
+	for step*2 <= n {
+		step *= 2
+	}
+
+	// Walk down the tree, skipping whole blocks that sum below num
+	pos := 0
+	for ; step > 0; step /= 2 {
+		if pos+step <= n && f.tree[pos+step] < num {
+			pos += step
+			num -= f.tree[pos]
+		}
+	}
+
+	return pos
+}
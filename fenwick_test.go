@@ -0,0 +1,43 @@
+package stairs
+
+import "testing"
+
+// TestFenwick checks prefix sums and searches
+// against a plain array as the tree grows.
+func TestFenwick(t *testing.T) {
+	var f fenwick
+	var plain []int
+
+	for i := 0; i < 37; i++ {
+		pos := f.grow()
+		if pos != i {
+			t.Fatalf("grow returned %d, want %d", pos, i)
+		}
+
+		weight := i%5 + 1
+		f.add(pos, weight)
+		plain = append(plain, weight)
+
+		// Every prefix must match the plain sum
+		sum := 0
+		for n := 0; n <= len(plain); n++ {
+			if got := f.prefix(n); got != sum {
+				t.Fatalf("prefix(%d) = %d, want %d", n, got, sum)
+			}
+			if n < len(plain) {
+				sum += plain[n]
+			}
+		}
+
+		// Every cumulative weight must land on the position that owns it
+		sum = 0
+		for p, w := range plain {
+			for num := sum + 1; num <= sum+w; num++ {
+				if got := f.search(num); got != p {
+					t.Fatalf("search(%d) = %d, want %d", num, got, p)
+				}
+			}
+			sum += w
+		}
+	}
+}
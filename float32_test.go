@@ -0,0 +1,95 @@
+package stairs
+
+import (
+	"errors"
+	"math/rand"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+// TestBuildFloat32 checks that a single-precision array samples
+// the same way as its double-precision equivalent.
+func TestBuildFloat32(t *testing.T) {
+	w := WeightedItemsFloat32{
+		Weights: []float32{5.75, 1.5, 2.25},
+		Indices: []int32{2, 0, 1},
+	}
+	w64 := WeightedItemsFloat{{1.5, 0}, {2.25, 1}, {5.75, 2}}
+
+	f, err := w.BuildCDFWithRand(rand.New(rand.NewSource(17)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f64, err := w64.BuildCDFWithRand(rand.New(rand.NewSource(17)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if f() != f64() {
+			t.Fatal("float32 and float64 samplers disagree")
+		}
+	}
+}
+
+// TestValidateFloat32 checks that invalid single-precision arrays are rejected.
+func TestValidateFloat32(t *testing.T) {
+	tests := []struct {
+		s    WeightedItemsFloat32
+		want error
+	}{
+		{WeightedItemsFloat32{}, ErrEmpty},
+		{WeightedItemsFloat32{[]float32{1, 0}, []int32{0, 1}}, ErrNonPositiveWeight},
+		{WeightedItemsFloat32{[]float32{1, 2}, []int32{0, 0}}, ErrDuplicateIndex},
+		{WeightedItemsFloat32{[]float32{1, 2}, []int32{0}}, ErrLengthMismatch},
+	}
+
+	for _, test := range tests {
+		if _, err := test.s.BuildCDF(); !errors.Is(err, test.want) {
+			t.Errorf("%v: got %v, want %v", test.s, err, test.want)
+		}
+	}
+}
+
+// TestFloat32Size checks that each item takes half the memory
+// of a WeightedItemFloat.
+func TestFloat32Size(t *testing.T) {
+	perItem := unsafe.Sizeof(float32(0)) + unsafe.Sizeof(int32(0))
+	if perItem*2 != unsafe.Sizeof(WeightedItemFloat{}) {
+		t.Errorf("got %d bytes per item, want half of %d", perItem, unsafe.Sizeof(WeightedItemFloat{}))
+	}
+}
+
+// TestFloat32BuildAllocs checks that building doesn't allocate more
+// than building the same items from a WeightedItemsFloat, which only
+// copies them once into the cumulative array.
+func TestFloat32BuildAllocs(t *testing.T) {
+	const n = 100000
+
+	w := WeightedItemsFloat32{make([]float32, n), make([]int32, n)}
+	w64 := make(WeightedItemsFloat, n)
+	for i := 0; i < n; i++ {
+		w.Weights[i] = float32(i%100 + 1)
+		w.Indices[i] = int32(i)
+		w64[i] = WeightedItemFloat{float64(i%100 + 1), i}
+	}
+
+	got := allocatedBytes(func() { w.BuildCDF() })
+	want := allocatedBytes(func() { w64.BuildCDF() })
+
+	// Leave a little room for allocations that vary from run to run
+	if float64(got) > 1.1*float64(want) {
+		t.Errorf("got %d bytes allocated, want at most about %d", got, want)
+	}
+}
+
+// allocatedBytes returns how many bytes f allocates.
+func allocatedBytes(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
@@ -0,0 +1,60 @@
+package stairs
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// String returns the array as an aligned table of each item's index,
+// weight and probability, in array order, for logs and test output.
+// If the array can't be built into a CDF, probabilities show as "-".
+func (s WeightedItems) String() string {
+	var total int
+	err := s.Validate()
+	if err == nil {
+		total, err = s.total()
+	}
+
+	return formatTable(len(s), func(i int) (int, string, string) {
+		p := "-"
+		if err == nil {
+			p = fmt.Sprintf("%.4f", float64(s[i].Weight)/float64(total))
+		}
+		return s[i].Index, fmt.Sprint(s[i].Weight), p
+	})
+}
+
+// String returns the array as an aligned table of each item's index,
+// weight and probability, in array order, for logs and test output.
+// If the array can't be built into a CDF, probabilities show as "-".
+func (s WeightedItemsFloat) String() string {
+	var total float64
+	err := s.Validate()
+	if err == nil {
+		total, err = s.total()
+	}
+
+	return formatTable(len(s), func(i int) (int, string, string) {
+		p := "-"
+		if err == nil {
+			p = fmt.Sprintf("%.4f", s[i].Weight/total)
+		}
+		return s[i].Index, fmt.Sprintf("%g", s[i].Weight), p
+	})
+}
+
+// formatTable lays out n rows of index, weight and probability under a header.
+func formatTable(n int, row func(int) (int, string, string)) string {
+	var b strings.Builder
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INDEX\tWEIGHT\tPROBABILITY")
+	for i := 0; i < n; i++ {
+		index, weight, p := row(i)
+		fmt.Fprintf(w, "%d\t%s\t%s\n", index, weight, p)
+	}
+	w.Flush()
+
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package stairs
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// TestString checks the table layout for both kinds of array.
+func TestString(t *testing.T) {
+	want := "INDEX  WEIGHT  PROBABILITY\n" +
+		"0      1       0.1250\n" +
+		"1      2       0.2500\n" +
+		"2      5       0.6250\n"
+	if got := buildWeightedArray().String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "INDEX  WEIGHT  PROBABILITY\n" +
+		"10     0.5     0.2500\n" +
+		"3      1.5     0.7500\n"
+	if got := fmt.Sprint(WeightedItemsFloat{{0.5, 10}, {1.5, 3}}); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestStringInvalid checks that arrays that can't be
+// built still print, without probabilities.
+func TestStringInvalid(t *testing.T) {
+	want := "INDEX  WEIGHT  PROBABILITY\n" +
+		"0      0       -\n"
+	if got := (WeightedItems{{0, 0}}).String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if got := (WeightedItemsFloat{}).String(); got != "INDEX  WEIGHT  PROBABILITY\n" {
+		t.Errorf("got %q", got)
+	}
+
+	want = "INDEX  WEIGHT  PROBABILITY\n" +
+		"1      1       -\n" +
+		"1      3       -\n"
+	if got := (WeightedItems{{1, 1}, {3, 1}}).String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "INDEX  WEIGHT  PROBABILITY\n" +
+		"0      1       -\n" +
+		"1      NaN     -\n"
+	if got := (WeightedItemsFloat{{1, 0}, {math.NaN(), 1}}).String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	want = "INDEX  WEIGHT                   PROBABILITY\n" +
+		"0      1.7976931348623157e+308  -\n" +
+		"1      1.7976931348623157e+308  -\n" +
+		"2      1.7976931348623157e+308  -\n"
+	huge := WeightedItemsFloat{{math.MaxFloat64, 0}, {math.MaxFloat64, 1}, {math.MaxFloat64, 2}}
+	if got := huge.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+package stairs
+
+import (
+	"iter"
+	"math"
+	"sort"
+)
+
+// FromMap builds a weighted array from a map of index to weight.
+// Keys are sorted first, so the result (and any CDF built from it with
+// a seeded generator) is the same no matter the map's iteration order.
+func FromMap(m map[int]int) (WeightedItems, error) {
+	// Reject empty maps
+	if len(m) <= 0 {
+		return nil, ErrEmpty
+	}
+
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	w := make(WeightedItems, 0, len(keys))
+	for _, k := range keys {
+		if k < 0 {
+			return nil, ErrNegativeIndex
+		}
+		if m[k] <= 0 {
+			return nil, ErrNonPositiveWeight
+		}
+		w = append(w, WeightedItem{m[k], k})
+	}
+
+	return w, nil
+}
+
+// BuildFromSlices builds a sampler where weights[i] is the weight of index i,
+// without constructing WeightedItems by hand. Use BuildFromValues to have
+// the sampler return entries of a parallel values slice directly.
+func BuildFromSlices(weights []int) (func() int, error) {
+	return FromWeights(weights).BuildCDF()
+}
+
+// FromWeights returns a weighted array where weights[i] is the weight of
+// index i. It doesn't validate the weights; BuildCDF does that.
+func FromWeights(weights []int) WeightedItems {
+	w := make(WeightedItems, len(weights))
+	for i, weight := range weights {
+		w[i] = WeightedItem{weight, i}
+	}
+	return w
+}
+
+// FromFloatWeights returns a floating-point weighted array where weights[i]
+// is the weight of index i. It doesn't validate the weights; BuildCDF does that.
+func FromFloatWeights(weights []float64) WeightedItemsFloat {
+	w := make(WeightedItemsFloat, len(weights))
+	for i, weight := range weights {
+		w[i] = WeightedItemFloat{weight, i}
+	}
+	return w
+}
+
+// BuildFromSeq builds a sampler from a sequence of (index, weight) pairs,
+// such as rows from a cursor or a generator, without the caller building
+// a slice first. The sequence is read to the end before any sampling and
+// is validated like BuildCDF: it must not be empty, and every weight
+// must be positive.
+func BuildFromSeq(seq iter.Seq2[int, int]) (func() int, error) {
+	var w WeightedItems
+	for index, weight := range seq {
+		w = append(w, WeightedItem{weight, index})
+	}
+	return w.BuildCDF()
+}
+
+// BuildFromProbabilities builds a sampler where p[i] is the probability of
+// index i. The probabilities must be non-negative and sum to 1 within
+// EPSILON, which catches inputs that were meant to be normalized but
+// aren't. Indices with zero probability are never selected.
+func BuildFromProbabilities(p []float64) (func() int, error) {
+	var sum kahanSum
+	for _, prob := range p {
+		if !(prob >= 0) {
+			return nil, ErrNonPositiveWeight
+		}
+		sum.add(prob)
+	}
+
+	if math.Abs(sum.sum-1) > EPSILON {
+		return nil, ErrNotNormalized
+	}
+
+	w := make(WeightedItemsFloat, len(p))
+	for i, prob := range p {
+		w[i] = WeightedItemFloat{prob, i}
+	}
+	return w.BuildCDFSkipZero()
+}
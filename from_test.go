@@ -0,0 +1,139 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestFromMap checks that a map is converted to
+// a weighted array ordered by key.
+func TestFromMap(t *testing.T) {
+	w, err := FromMap(map[int]int{4: 1, 0: 2, 9: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := WeightedItems{{2, 0}, {1, 4}, {5, 9}}
+	if len(w) != len(want) {
+		t.Fatalf("got %v, want %v", w, want)
+	}
+
+	for i := range want {
+		if w[i] != want[i] {
+			t.Errorf("got %v, want %v", w, want)
+		}
+	}
+}
+
+// TestFromMapEmpty checks that an empty map is rejected.
+func TestFromMapEmpty(t *testing.T) {
+	if _, err := FromMap(map[int]int{}); !errors.Is(err, ErrEmpty) {
+		t.Fail()
+	}
+}
+
+// TestFromMapZeroWeight checks that a map with a zero weight is rejected.
+func TestFromMapZeroWeight(t *testing.T) {
+	if _, err := FromMap(map[int]int{1: 3, 2: 0}); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Fail()
+	}
+}
+
+// TestBuildFromSlices checks that indices come from
+// the positions in the weights slice.
+func TestBuildFromSlices(t *testing.T) {
+	f, err := BuildFromSlices([]int{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if f() != 0 {
+			t.Fail()
+		}
+	}
+
+	if _, err := BuildFromSlices(nil); !errors.Is(err, ErrEmpty) {
+		t.Error("built from no weights")
+	}
+}
+
+// TestBuildFromProbabilities checks that normalized probabilities
+// are accepted and zero-probability indices never come up.
+func TestBuildFromProbabilities(t *testing.T) {
+	f, err := BuildFromProbabilities([]float64{0.2, 0, 0.3, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if index := f(); index == 1 || index < 0 || index > 3 {
+			t.Fatalf("selected index %d", index)
+		}
+	}
+}
+
+// TestBuildFromProbabilitiesInvalid checks that probabilities which
+// don't sum to one, or are negative, are rejected.
+func TestBuildFromProbabilitiesInvalid(t *testing.T) {
+	if _, err := BuildFromProbabilities([]float64{0.2, 0.3}); !errors.Is(err, ErrNotNormalized) {
+		t.Error("accepted probabilities summing to 0.5")
+	}
+
+	if _, err := BuildFromProbabilities([]float64{1.5, -0.5}); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Error("accepted a negative probability")
+	}
+}
+
+// TestBuildFromSeq checks that a sampler can be built from an
+// iterator, and that bad sequences are rejected.
+func TestBuildFromSeq(t *testing.T) {
+	seq := func(pairs ...[2]int) func(func(int, int) bool) {
+		return func(yield func(int, int) bool) {
+			for _, p := range pairs {
+				if !yield(p[0], p[1]) {
+					return
+				}
+			}
+		}
+	}
+
+	f, err := BuildFromSeq(seq([2]int{3, 1}, [2]int{7, 4}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if index := f(); index != 3 && index != 7 {
+			t.Fatalf("got index %d", index)
+		}
+	}
+
+	if _, err := BuildFromSeq(seq()); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+
+	if _, err := BuildFromSeq(seq([2]int{0, 1}, [2]int{1, 0})); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Errorf("got %v, want ErrNonPositiveWeight", err)
+	}
+}
+
+// TestFromWeights checks that indices follow slice positions
+// and that invalid weights are left for BuildCDF to reject.
+func TestFromWeights(t *testing.T) {
+	w := FromWeights([]int{4, 0, 9})
+	want := WeightedItems{{4, 0}, {0, 1}, {9, 2}}
+	for i := range want {
+		if w[i] != want[i] {
+			t.Errorf("got %v, want %v", w, want)
+		}
+	}
+
+	if _, err := w.BuildCDF(); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Errorf("got %v, want ErrNonPositiveWeight", err)
+	}
+
+	f := FromFloatWeights([]float64{0.5, 1.5})
+	if len(f) != 2 || f[1] != (WeightedItemFloat{1.5, 1}) {
+		t.Errorf("got %v", f)
+	}
+}
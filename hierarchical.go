@@ -0,0 +1,68 @@
+package stairs
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+)
+
+// HierarchicalSampler picks a group in proportion to the total weight of
+// its items, then an item within that group in proportion to its weight,
+// like picking a category and then a product. Every item ends up selected
+// with the same chance as in a single CDF over all of them.
+//
+// A HierarchicalSampler is not safe for concurrent use.
+type HierarchicalSampler[G cmp.Ordered] struct {
+	groups []G
+	top    *CDF
+	items  []*CDF
+}
+
+// NewHierarchicalSampler builds the group CDF and a CDF for each group
+// once, up front. Groups are ordered by key, so a seeded generator gives
+// the same results whatever the map's iteration order. If r is nil, a
+// generator seeded from the current time is used.
+func NewHierarchicalSampler[G cmp.Ordered](groups map[G]WeightedItems, r *rand.Rand) (*HierarchicalSampler[G], error) {
+	// Reject empty maps
+	if len(groups) <= 0 {
+		return nil, ErrEmpty
+	}
+
+	if r == nil {
+		r = newRand()
+	}
+
+	h := &HierarchicalSampler[G]{
+		groups: make([]G, 0, len(groups)),
+		items:  make([]*CDF, 0, len(groups)),
+	}
+	for g := range groups {
+		h.groups = append(h.groups, g)
+	}
+	slices.Sort(h.groups)
+
+	totals := make(WeightedItems, len(h.groups))
+	for i, g := range h.groups {
+		c, err := groups[g].build(r)
+		if err != nil {
+			return nil, err
+		}
+		h.items = append(h.items, c)
+		totals[i] = WeightedItem{c.total(), i}
+	}
+
+	top, err := totals.build(r)
+	if err != nil {
+		return nil, err
+	}
+	h.top = top
+
+	return h, nil
+}
+
+// Sample returns a random group and the original index
+// of a random item within it.
+func (h *HierarchicalSampler[G]) Sample() (G, int) {
+	g := h.top.Sample()
+	return h.groups[g], h.items[g].Sample()
+}
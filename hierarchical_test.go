@@ -0,0 +1,47 @@
+package stairs
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// TestHierarchicalSampler checks that groups are picked by their
+// total weight and items only come from their own group.
+func TestHierarchicalSampler(t *testing.T) {
+	h, err := NewHierarchicalSampler(map[string]WeightedItems{
+		"books": {{1, 0}, {2, 1}},
+		"games": {{9, 5}},
+	}, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 12000; i++ {
+		g, index := h.Sample()
+		counts[g]++
+
+		if g == "books" && index != 0 && index != 1 || g == "games" && index != 5 {
+			t.Fatalf("got index %d from group %q", index, g)
+		}
+	}
+
+	// Books hold 3 of the 12 units of weight
+	if counts["books"] < 2500 || counts["books"] > 3500 {
+		t.Errorf("got %v", counts)
+	}
+}
+
+// TestHierarchicalSamplerInvalid checks that empty maps
+// and invalid groups are rejected.
+func TestHierarchicalSamplerInvalid(t *testing.T) {
+	if _, err := NewHierarchicalSampler(map[int]WeightedItems{}, nil); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+
+	groups := map[int]WeightedItems{1: {{1, 0}}, 2: {{0, 0}}}
+	if _, err := NewHierarchicalSampler(groups, nil); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Errorf("got %v, want ErrNonPositiveWeight", err)
+	}
+}
@@ -0,0 +1,37 @@
+package stairs
+
+// BuildCDFItems works like BuildCDF, but the returned function gives back
+// the whole selected item, with its original weight rather than the
+// cumulative total the sampler searches through.
+func (s WeightedItems) BuildCDFItems() (func() WeightedItem, error) {
+	c, err := s.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort a copy the same way, so it lines up with the CDF's positions
+	items := append(make(WeightedItems, 0, len(s)), s...)
+	sortAscending(items)
+
+	return func() WeightedItem {
+		return items[c.draw()]
+	}, nil
+}
+
+// BuildCDFItems works like BuildCDF, but the returned function gives back
+// the whole selected item, with its original weight rather than the
+// cumulative total the sampler searches through.
+func (s WeightedItemsFloat) BuildCDFItems() (func() WeightedItemFloat, error) {
+	c, err := s.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort a copy the same way, so it lines up with the CDF's positions
+	items := append(make(WeightedItemsFloat, 0, len(s)), s...)
+	sortAscending(items)
+
+	return func() WeightedItemFloat {
+		return items[c.draw()]
+	}, nil
+}
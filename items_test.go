@@ -0,0 +1,39 @@
+package stairs
+
+import "testing"
+
+// TestBuildCDFItems checks that each returned item
+// carries its original weight.
+func TestBuildCDFItems(t *testing.T) {
+	w := buildWeightedArray()
+
+	f, err := w.BuildCDFItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		item := f()
+		if item != w[item.Index] {
+			t.Fatalf("got %v, want %v", item, w[item.Index])
+		}
+	}
+}
+
+// TestBuildCDFItemsFloat checks that each returned item
+// carries its exact original weight.
+func TestBuildCDFItemsFloat(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	f, err := w.BuildCDFItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		item := f()
+		if item != w[item.Index] {
+			t.Fatalf("got %v, want %v", item, w[item.Index])
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package stairs
+
+import "encoding/json"
+
+// MarshalJSON encodes the array as a list of {"weight", "index"} objects.
+func (s WeightedItems) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]WeightedItem(s))
+}
+
+// UnmarshalJSON decodes a list of {"weight", "index"} objects,
+// rejecting non-positive weights and negative indices.
+func (s *WeightedItems) UnmarshalJSON(data []byte) error {
+	var items []WeightedItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Weight <= 0 {
+			return ErrNonPositiveWeight
+		}
+		if item.Index < 0 {
+			return ErrNegativeIndex
+		}
+	}
+
+	*s = items
+	return nil
+}
+
+// MarshalJSON encodes the array as a list of {"weight", "index"} objects.
+func (s WeightedItemsFloat) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]WeightedItemFloat(s))
+}
+
+// UnmarshalJSON decodes a list of {"weight", "index"} objects,
+// rejecting non-positive weights and negative indices.
+func (s *WeightedItemsFloat) UnmarshalJSON(data []byte) error {
+	var items []WeightedItemFloat
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Weight <= 0 {
+			return ErrNonPositiveWeight
+		}
+		if item.Index < 0 {
+			return ErrNegativeIndex
+		}
+	}
+
+	*s = items
+	return nil
+}
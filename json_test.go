@@ -0,0 +1,80 @@
+package stairs
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// TestJSONRoundTrip checks that a weighted array survives
+// encoding and decoding and still samples the same way.
+func TestJSONRoundTrip(t *testing.T) {
+	w := buildWeightedArray()
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `[{"weight":1,"index":0},{"weight":2,"index":1},{"weight":5,"index":2}]` {
+		t.Errorf("unexpected encoding %s", data)
+	}
+
+	var decoded WeightedItems
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	f1, _ := w.BuildCDFWithRand(rand.New(rand.NewSource(7)))
+	f2, err := decoded.BuildCDFWithRand(rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if f1() != f2() {
+			t.Fatal("decoded array samples differently")
+		}
+	}
+}
+
+// TestJSONRoundTripFloat checks that a floating-point
+// weighted array survives encoding and decoding.
+func TestJSONRoundTripFloat(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded WeightedItemsFloat
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decoded) != len(w) {
+		t.Fatalf("got %v, want %v", decoded, w)
+	}
+
+	for i := range w {
+		if decoded[i] != w[i] {
+			t.Errorf("got %v, want %v", decoded, w)
+		}
+	}
+}
+
+// TestJSONInvalid checks that decoding rejects
+// non-positive weights and negative indices.
+func TestJSONInvalid(t *testing.T) {
+	var w WeightedItems
+	if err := json.Unmarshal([]byte(`[{"weight":0,"index":0}]`), &w); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Error("decoded a zero weight")
+	}
+
+	var f WeightedItemsFloat
+	if err := json.Unmarshal([]byte(`[{"weight":1.5,"index":-1}]`), &f); !errors.Is(err, ErrNegativeIndex) {
+		t.Error("decoded a negative index")
+	}
+}
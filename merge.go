@@ -0,0 +1,91 @@
+package stairs
+
+// MergedIndex records where an item in a merged array came from.
+type MergedIndex struct {
+	// Source is 0 if the item came from the first array, 1 if the second
+	Source int
+	// Index is the item's index in its source array
+	Index int
+}
+
+// Merge combines two weighted arrays into one. The items are re-indexed
+// 0, 1, 2... in order, a's items first, so the result never has duplicate
+// indices. The returned mapping gives, for each new index, the array and
+// index the item came from.
+func Merge(a, b WeightedItems) (WeightedItems, []MergedIndex, error) {
+	// Reject an empty result
+	if len(a)+len(b) <= 0 {
+		return nil, nil, ErrEmpty
+	}
+
+	merged := make(WeightedItems, 0, len(a)+len(b))
+	sources := make([]MergedIndex, 0, len(a)+len(b))
+
+	for source, items := range []WeightedItems{a, b} {
+		for _, item := range items {
+			sources = append(sources, MergedIndex{source, item.Index})
+			merged = append(merged, WeightedItem{item.Weight, len(merged)})
+		}
+	}
+
+	return merged, sources, nil
+}
+
+// MergeFloat combines two floating-point weighted arrays
+// into one, re-indexing them in the same way as Merge.
+func MergeFloat(a, b WeightedItemsFloat) (WeightedItemsFloat, []MergedIndex, error) {
+	// Reject an empty result
+	if len(a)+len(b) <= 0 {
+		return nil, nil, ErrEmpty
+	}
+
+	merged := make(WeightedItemsFloat, 0, len(a)+len(b))
+	sources := make([]MergedIndex, 0, len(a)+len(b))
+
+	for source, items := range []WeightedItemsFloat{a, b} {
+		for _, item := range items {
+			sources = append(sources, MergedIndex{source, item.Index})
+			merged = append(merged, WeightedItemFloat{item.Weight, len(merged)})
+		}
+	}
+
+	return merged, sources, nil
+}
+
+// Intersect combines two floating-point weighted arrays over the indices
+// present in both, giving each the product of its two weights, which
+// models independent preference signals. Items keep a's order. It
+// returns ErrEmpty if the arrays share no indices.
+func Intersect(a, b WeightedItemsFloat) (WeightedItemsFloat, error) {
+	return IntersectWith(a, b, func(x, y float64) float64 { return x * y })
+}
+
+// IntersectWith works like Intersect, but gives each shared index the
+// weight combine returns for its weight in a and its weight in b, such as
+// their sum. Both arrays are validated like in BuildCDF first.
+func IntersectWith(a, b WeightedItemsFloat, combine func(x, y float64) float64) (WeightedItemsFloat, error) {
+	for _, s := range []WeightedItemsFloat{a, b} {
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	weights := make(map[int]float64, len(b))
+	for _, item := range b {
+		weights[item.Index] = item.Weight
+	}
+
+	var out WeightedItemsFloat
+	for _, item := range a {
+		if weight, ok := weights[item.Index]; ok {
+			out = append(out, WeightedItemFloat{combine(item.Weight, weight), item.Index})
+		}
+	}
+
+	// Reject an empty result
+	if len(out) <= 0 {
+		return nil, ErrEmpty
+	}
+
+	return out, nil
+}
@@ -0,0 +1,88 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMerge checks that merged items are re-indexed
+// and mapped back to their sources.
+func TestMerge(t *testing.T) {
+	a := WeightedItems{{1, 0}, {2, 1}}
+	b := WeightedItems{{3, 0}, {4, 7}}
+
+	merged, sources, err := Merge(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantItems := WeightedItems{{1, 0}, {2, 1}, {3, 2}, {4, 3}}
+	wantSources := []MergedIndex{{0, 0}, {0, 1}, {1, 0}, {1, 7}}
+	for i := range wantItems {
+		if merged[i] != wantItems[i] || sources[i] != wantSources[i] {
+			t.Errorf("got %v and %v", merged, sources)
+		}
+	}
+
+	// Overlapping indices in the inputs must not break building
+	if _, err := merged.BuildCDF(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestMergeFloat checks that merged floating-point
+// items are re-indexed and mapped back to their sources.
+func TestMergeFloat(t *testing.T) {
+	a := WeightedItemsFloat{{1.5, 3}}
+	b := WeightedItemsFloat{{2.5, 3}}
+
+	merged, sources, err := MergeFloat(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if merged[0] != (WeightedItemFloat{1.5, 0}) || merged[1] != (WeightedItemFloat{2.5, 1}) {
+		t.Errorf("got %v", merged)
+	}
+
+	if sources[0] != (MergedIndex{0, 3}) || sources[1] != (MergedIndex{1, 3}) {
+		t.Errorf("got %v", sources)
+	}
+}
+
+// TestMergeEmpty checks that merging two empty arrays is rejected.
+func TestMergeEmpty(t *testing.T) {
+	if _, _, err := Merge(nil, nil); !errors.Is(err, ErrEmpty) {
+		t.Fail()
+	}
+}
+
+// TestIntersect checks that only shared indices are kept, with
+// their weights multiplied or combined as asked.
+func TestIntersect(t *testing.T) {
+	a := WeightedItemsFloat{{2, 0}, {3, 1}, {4, 2}}
+	b := WeightedItemsFloat{{5, 2}, {10, 0}, {1, 9}}
+
+	got, err := Intersect(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := WeightedItemsFloat{{20, 0}, {20, 2}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	sum := func(x, y float64) float64 { return x + y }
+	got, err = IntersectWith(a, b, sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = WeightedItemsFloat{{12, 0}, {9, 2}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := Intersect(a, WeightedItemsFloat{{1, 5}}); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+}
@@ -0,0 +1,247 @@
+package stairs
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Multinomial returns how many times each item is selected over n
+// independent draws, without making the draws one at a time. The result
+// is indexed by each item's Index field, like Probabilities, and adds up
+// to n. It takes O(k log n) time for k items, so it suits very large n.
+func (s WeightedItems) Multinomial(n int) ([]int, error) {
+	return s.MultinomialWithRand(n, newRand())
+}
+
+// MultinomialWithRand works like Multinomial, but draws
+// from the given random number generator.
+func (s WeightedItems) MultinomialWithRand(n int, r *rand.Rand) ([]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.total(); err != nil {
+		return nil, err
+	}
+
+	weights := make([]float64, len(s))
+	indices := make([]int, len(s))
+	for i, item := range s {
+		weights[i] = float64(item.Weight)
+		indices[i] = item.Index
+	}
+
+	return multinomial(n, weights, indices, r)
+}
+
+// Multinomial returns how many times each item is selected over n
+// independent draws, without making the draws one at a time. The result
+// is indexed by each item's Index field, like Probabilities, and adds up
+// to n. It takes O(k log n) time for k items, so it suits very large n.
+func (s WeightedItemsFloat) Multinomial(n int) ([]int, error) {
+	return s.MultinomialWithRand(n, newRand())
+}
+
+// MultinomialWithRand works like Multinomial, but draws
+// from the given random number generator.
+func (s WeightedItemsFloat) MultinomialWithRand(n int, r *rand.Rand) ([]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.total(); err != nil {
+		return nil, err
+	}
+
+	weights := make([]float64, len(s))
+	indices := make([]int, len(s))
+	for i, item := range s {
+		weights[i] = item.Weight
+		indices[i] = item.Index
+	}
+
+	return multinomial(n, weights, indices, r)
+}
+
+// MultinomialWithFloors works like Multinomial, but guarantees each item
+// at least floors[i] selections, where floors[i] belongs to s[i]. The
+// floors are given out first, then the remaining draws are split in
+// proportion to the weights. It fails if the floors add up to more than n.
+func (s WeightedItems) MultinomialWithFloors(n int, floors []int) ([]int, error) {
+	rest, err := afterFloors(n, floors, len(s))
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.Multinomial(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range s {
+		counts[item.Index] += floors[i]
+	}
+
+	return counts, nil
+}
+
+// MultinomialWithFloors works like Multinomial, but guarantees each item
+// at least floors[i] selections, where floors[i] belongs to s[i]. The
+// floors are given out first, then the remaining draws are split in
+// proportion to the weights. It fails if the floors add up to more than n.
+func (s WeightedItemsFloat) MultinomialWithFloors(n int, floors []int) ([]int, error) {
+	rest, err := afterFloors(n, floors, len(s))
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.Multinomial(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range s {
+		counts[item.Index] += floors[i]
+	}
+
+	return counts, nil
+}
+
+// afterFloors returns how many of n draws are left once the floors for
+// size items are given out.
+func afterFloors(n int, floors []int, size int) (int, error) {
+	if n < 0 {
+		return 0, ErrNegativeCount
+	}
+
+	if len(floors) != size {
+		return 0, ErrLengthMismatch
+	}
+
+	rest := n
+	for _, floor := range floors {
+		if floor < 0 {
+			return 0, ErrNegativeCount
+		}
+
+		// Make sure the floors fit in n
+		if floor > rest {
+			return 0, ErrOutOfRange
+		}
+		rest -= floor
+	}
+
+	return rest, nil
+}
+
+// multinomial splits n draws between the items, where weights[i] belongs
+// to the item at indices[i]. Each item in turn takes a binomial share of
+// the draws left, given its weight over the weight of every item not yet
+// visited, and the last item takes whatever remains.
+func multinomial(n int, weights []float64, indices []int, r *rand.Rand) ([]int, error) {
+	// Size the result to fit the largest index
+	size := 0
+	for _, index := range indices {
+		if index < 0 {
+			return nil, ErrNegativeIndex
+		}
+		if index >= size {
+			size = index + 1
+		}
+	}
+
+	// remaining[i] is the weight of items i onwards
+	remaining := make([]float64, len(weights)+1)
+	for i := len(weights) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + weights[i]
+	}
+
+	counts := make([]int, size)
+	left := n
+	for i := 0; i < len(weights)-1 && left > 0; i++ {
+		k := binomial(left, weights[i]/remaining[i], r)
+		counts[indices[i]] = k
+		left -= k
+	}
+	counts[indices[len(indices)-1]] += left
+
+	return counts, nil
+}
+
+// directBinomial is the number of trials below which
+// binomial flips each one instead of splitting further.
+const directBinomial = 64
+
+// binomial returns the number of successes in n trials that each
+// succeed with probability p. Large n are split in O(log n) steps
+// using the order statistics of a beta distribution, as in Knuth's
+// The Art of Computer Programming, Volume 2, section 3.4.1.
+func binomial(n int, p float64, r *rand.Rand) int {
+	if !(p > 0) {
+		return 0
+	}
+	if p >= 1 {
+		return n
+	}
+
+	k := 0
+	for n > directBinomial {
+		// x is the a-th smallest of n uniform values; the successes are
+		// the values below p, so recurse into whichever side holds p
+		a := 1 + n/2
+		b := n + 1 - a
+		x := betaVariate(float64(a), float64(b), r)
+
+		if x >= p {
+			n = a - 1
+			p /= x
+		} else {
+			k += a
+			n = b - 1
+			p = (p - x) / (1 - x)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if r.Float64() < p {
+			k++
+		}
+	}
+
+	return k
+}
+
+// betaVariate returns a beta-distributed value with shapes a, b >= 1.
+func betaVariate(a, b float64, r *rand.Rand) float64 {
+	x := gammaVariate(a, r)
+	return x / (x + gammaVariate(b, r))
+}
+
+// gammaVariate returns a gamma-distributed value with the given shape,
+// which must be at least 1, using Marsaglia and Tsang's method.
+func gammaVariate(shape float64, r *rand.Rand) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+
+		u := r.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < x*x/2+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package stairs
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestMultinomial checks that the counts add up to n, line up
+// with the original indices and follow the weights.
+func TestMultinomial(t *testing.T) {
+	w := WeightedItems{{1, 4}, {2, 0}, {5, 2}}
+	n := 8000000
+
+	counts, err := w.MultinomialWithRand(n, rand.New(rand.NewSource(21)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(counts) != 5 || counts[1] != 0 || counts[3] != 0 {
+		t.Fatalf("got %v, want counts at indices 0, 2 and 4", counts)
+	}
+
+	sum := 0
+	for _, k := range counts {
+		sum += k
+	}
+	if sum != n {
+		t.Errorf("got %d draws, want %d", sum, n)
+	}
+
+	// Each count should be within a few standard deviations of n*p
+	for _, item := range w {
+		p := float64(item.Weight) / 8
+		mean := float64(n) * p
+		sd := math.Sqrt(mean * (1 - p))
+		if math.Abs(float64(counts[item.Index])-mean) > 5*sd {
+			t.Errorf("index %d: got %d, want about %v", item.Index, counts[item.Index], mean)
+		}
+	}
+}
+
+// TestMultinomialFloat checks small and empty draws.
+func TestMultinomialFloat(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	counts, err := w.Multinomial(0)
+	if err != nil || len(counts) != 3 || counts[0]+counts[1]+counts[2] != 0 {
+		t.Errorf("got %v, %v; want no draws", counts, err)
+	}
+
+	counts, err = w.Multinomial(10)
+	if err != nil || counts[0]+counts[1]+counts[2] != 10 {
+		t.Errorf("got %v, %v; want 10 draws", counts, err)
+	}
+
+	if _, err := w.Multinomial(-1); !errors.Is(err, ErrNegativeCount) {
+		t.Errorf("got %v, want ErrNegativeCount", err)
+	}
+
+	for _, huge := range []WeightedItemsFloat{
+		{{math.MaxFloat64, 0}, {math.MaxFloat64, 1}},
+		{{1, 0}, {math.Inf(1), 1}},
+	} {
+		if _, err := huge.Multinomial(1000); !errors.Is(err, ErrWeightOverflow) {
+			t.Errorf("got %v for an overflowed total, want ErrWeightOverflow", err)
+		}
+	}
+}
+
+// TestBinomial checks the mean of large binomial draws.
+func TestBinomial(t *testing.T) {
+	r := rand.New(rand.NewSource(22))
+
+	sum := 0
+	for i := 0; i < 1000; i++ {
+		sum += binomial(100000, 0.3, r)
+	}
+
+	// The mean of the average is 30000, with a standard deviation near 4.6
+	if mean := float64(sum) / 1000; math.Abs(mean-30000) > 25 {
+		t.Errorf("got mean %v, want about 30000", mean)
+	}
+}
+
+// TestMultinomialWithFloors checks that every item gets
+// its floor and the counts still add up to n.
+func TestMultinomialWithFloors(t *testing.T) {
+	w := WeightedItems{{1, 3}, {1000, 1}}
+
+	counts, err := w.MultinomialWithFloors(100, []int{10, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[3] < 10 || counts[1]+counts[3] != 100 {
+		t.Errorf("got %v", counts)
+	}
+
+	// With no draws left over, the floors are the counts
+	counts, err = buildWeightedFloatArray().MultinomialWithFloors(6, []int{1, 2, 3})
+	if err != nil || counts[0] != 1 || counts[1] != 2 || counts[2] != 3 {
+		t.Errorf("got %v, %v; want [1 2 3]", counts, err)
+	}
+
+	if _, err := w.MultinomialWithFloors(5, []int{3, 3}); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want ErrOutOfRange", err)
+	}
+	if _, err := w.MultinomialWithFloors(5, []int{1}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("got %v, want ErrLengthMismatch", err)
+	}
+	if _, err := w.MultinomialWithFloors(5, []int{-1, 0}); !errors.Is(err, ErrNegativeCount) {
+		t.Errorf("got %v, want ErrNegativeCount", err)
+	}
+}
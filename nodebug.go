@@ -0,0 +1,7 @@
+//go:build !stairsdebug
+
+package stairs
+
+// debug enables extra checks that are too costly for normal builds.
+// Build or test with -tags stairsdebug to turn them on.
+const debug = false
@@ -0,0 +1,37 @@
+package stairs
+
+// NoSelection is the index returned by a sampler that has
+// nothing it can select. See WithEmptySentinel.
+const NoSelection = -1
+
+// Option configures how a sampler is built.
+type Option func(*options)
+
+// options holds the settings chosen by a list of Options.
+type options struct {
+	emptySentinel bool
+}
+
+// newOptions applies opts over the defaults.
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithEmptySentinel makes a builder that filters out items, such as
+// BuildCDFSkipZero, succeed even when every item is filtered out.
+// The returned function then always returns NoSelection (-1)
+// instead of the build failing with ErrEmpty.
+func WithEmptySentinel() Option {
+	return func(o *options) {
+		o.emptySentinel = true
+	}
+}
+
+// noSelection is the sampler used when nothing can be selected.
+func noSelection() int {
+	return NoSelection
+}
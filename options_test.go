@@ -0,0 +1,41 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestEmptySentinel checks that filtering out every item
+// returns the sentinel only when asked to.
+func TestEmptySentinel(t *testing.T) {
+	w := WeightedItems{{0, 0}, {0, 1}}
+
+	if _, err := w.BuildCDFSkipZero(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+
+	f, err := w.BuildCDFSkipZero(WithEmptySentinel())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if f() != NoSelection {
+			t.Fail()
+		}
+	}
+}
+
+// TestEmptySentinelFloat checks the sentinel for floating-point
+// arrays, and that it's unused while items remain.
+func TestEmptySentinelFloat(t *testing.T) {
+	f, err := WeightedItemsFloat{{0, 0}}.BuildCDFSkipZero(WithEmptySentinel())
+	if err != nil || f() != NoSelection {
+		t.Error("didn't return the sentinel")
+	}
+
+	f, err = WeightedItemsFloat{{0, 0}, {1.5, 1}}.BuildCDFSkipZero(WithEmptySentinel())
+	if err != nil || f() != 1 {
+		t.Error("returned the sentinel with an item left")
+	}
+}
@@ -0,0 +1,47 @@
+package stairs
+
+// SamplePage draws pageSize distinct indices, with the same distribution
+// as SampleKDistinct. Indices never repeat within a page, but the CDF's
+// weights are left intact, so any index can come up again on a later page.
+func (c *CDF) SamplePage(pageSize int) ([]int, error) {
+	if pageSize < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	if pageSize > len(c.items) {
+		return nil, ErrTooMany
+	}
+
+	weight := func(i int) float64 {
+		if i == 0 {
+			return float64(c.items[0].Weight)
+		}
+		return float64(c.items[i].Weight - c.items[i-1].Weight)
+	}
+	index := func(i int) int { return c.items[i].Index }
+
+	return gumbelTopK(len(c.items), weight, index, pageSize, c.r), nil
+}
+
+// SamplePage draws pageSize distinct indices, with the same distribution
+// as SampleKDistinct. Indices never repeat within a page, but the CDF's
+// weights are left intact, so any index can come up again on a later page.
+func (c *CDFFloat) SamplePage(pageSize int) ([]int, error) {
+	if pageSize < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	if pageSize > len(c.items) {
+		return nil, ErrTooMany
+	}
+
+	weight := func(i int) float64 {
+		if i == 0 {
+			return c.items[0].Weight
+		}
+		return c.items[i].Weight - c.items[i-1].Weight
+	}
+	index := func(i int) int { return c.items[i].Index }
+
+	return gumbelTopK(len(c.items), weight, index, pageSize, c.r), nil
+}
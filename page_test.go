@@ -0,0 +1,56 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSamplePage checks that pages have no repeats
+// while indices still reappear across pages.
+func TestSamplePage(t *testing.T) {
+	c, err := WeightedItems{{1, 0}, {2, 1}, {3, 2}, {4, 3}}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Reseed(13)
+
+	seen := make(map[int]int)
+	for page := 0; page < 50; page++ {
+		out, err := c.SamplePage(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inPage := make(map[int]bool)
+		for _, index := range out {
+			if inPage[index] {
+				t.Fatalf("page %v repeats index %d", out, index)
+			}
+			inPage[index] = true
+			seen[index]++
+		}
+	}
+
+	// With weights left intact, the heaviest index shows up on most pages
+	if seen[3] < 40 {
+		t.Errorf("heaviest index on %d of 50 pages", seen[3])
+	}
+}
+
+// TestSamplePageFloat checks that a full page of a floating-point
+// CDF holds every index once, and that oversized pages are rejected.
+func TestSamplePageFloat(t *testing.T) {
+	c, err := buildWeightedFloatArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := c.SamplePage(3)
+	if err != nil || len(out) != 3 || out[0]+out[1]+out[2] != 3 {
+		t.Errorf("got %v, %v", out, err)
+	}
+
+	if _, err := c.SamplePage(4); !errors.Is(err, ErrTooMany) {
+		t.Error("accepted a page larger than the CDF")
+	}
+}
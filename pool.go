@@ -0,0 +1,67 @@
+package stairs
+
+import "math/rand"
+
+// PoolSampler draws from a pool of consumable items, like dealing from a
+// weighted deck. Each item's weight is its remaining count, and every
+// draw uses up one of it. Draws take O(log n), and items whose count
+// reaches zero are simply never drawn again.
+type PoolSampler struct {
+	tree    fenwick
+	indices []int
+	total   int
+	r       *rand.Rand
+}
+
+// NewPoolSampler creates a pool where each item's weight is its starting
+// count. If r is nil, a generator seeded from the current time is used.
+func NewPoolSampler(items WeightedItems, r *rand.Rand) (*PoolSampler, error) {
+	total, err := items.total()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject items that point to the same index
+	if hasDuplicateIndex(len(items), func(i int) int { return items[i].Index }) {
+		return nil, ErrDuplicateIndex
+	}
+
+	if r == nil {
+		r = newRand()
+	}
+
+	p := &PoolSampler{
+		indices: make([]int, len(items)),
+		total:   total,
+		r:       r,
+	}
+
+	for i, item := range items {
+		p.tree.add(p.tree.grow(), item.Weight)
+		p.indices[i] = item.Index
+	}
+
+	return p, nil
+}
+
+// DrawAndDecrement picks an item according to the remaining counts,
+// uses one of it up and returns its original index.
+// It returns ErrExhausted once the pool is empty.
+func (p *PoolSampler) DrawAndDecrement() (int, error) {
+	if p.total <= 0 {
+		return 0, ErrExhausted
+	}
+
+	// Picking a random number in the range [1, remaining count + 1)
+	pos := p.tree.search(p.r.Intn(p.total) + 1)
+
+	p.tree.add(pos, -1)
+	p.total--
+
+	return p.indices[pos], nil
+}
+
+// Remaining returns the number of draws left in the pool.
+func (p *PoolSampler) Remaining() int {
+	return p.total
+}
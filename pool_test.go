@@ -0,0 +1,48 @@
+package stairs
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// TestPoolSampler checks that draining the pool returns
+// each index exactly as many times as its weight.
+func TestPoolSampler(t *testing.T) {
+	w := WeightedItems{{3, 0}, {1, 5}, {4, 2}}
+
+	p, err := NewPoolSampler(w, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[int]int)
+	for p.Remaining() > 0 {
+		index, err := p.DrawAndDecrement()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[index]++
+	}
+
+	for _, item := range w {
+		if counts[item.Index] != item.Weight {
+			t.Errorf("index %d drawn %d times, want %d", item.Index, counts[item.Index], item.Weight)
+		}
+	}
+
+	if _, err := p.DrawAndDecrement(); !errors.Is(err, ErrExhausted) {
+		t.Error("drew from an empty pool")
+	}
+}
+
+// TestPoolSamplerInvalid checks that invalid pools are rejected.
+func TestPoolSamplerInvalid(t *testing.T) {
+	if _, err := NewPoolSampler(WeightedItems{{1, 0}, {0, 1}}, nil); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Error("accepted a zero count")
+	}
+
+	if _, err := NewPoolSampler(WeightedItems{{1, 0}, {2, 0}}, nil); !errors.Is(err, ErrDuplicateIndex) {
+		t.Error("accepted a duplicate index")
+	}
+}
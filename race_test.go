@@ -0,0 +1,7 @@
+//go:build race
+
+package stairs
+
+// raceEnabled reports whether the race detector is on, which makes
+// sync.Pool drop items at random.
+const raceEnabled = true
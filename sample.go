@@ -0,0 +1,451 @@
+package stairs
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// SampleN draws n indices from the weighted array, with replacement.
+// The CDF is built once and reused for every draw.
+func (s WeightedItems) SampleN(n int) ([]int, error) {
+	return s.SampleNContext(context.Background(), n)
+}
+
+// SampleNContext works like SampleN, but stops early if ctx is canceled,
+// returning the draws made so far along with ctx.Err().
+// The context is checked once every 1024 draws.
+func (s WeightedItems) SampleNContext(ctx context.Context, n int) ([]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	f, err := s.BuildCDF()
+	if err != nil {
+		return nil, err
+	}
+
+	return drawN(ctx, f, n)
+}
+
+// SampleNSeeded works like SampleN, but draws from
+// rand.New(rand.NewSource(seed)), so the same array and seed always
+// produce the same indices, whatever order the items are given in.
+// That makes the output safe to record as golden test data.
+//
+// The sequence depends on math/rand's seeded source, which Go keeps
+// stable, and on how this package maps random numbers to items, so
+// golden data may need regenerating if either of those ever changes.
+func (s WeightedItems) SampleNSeeded(n int, seed int64) ([]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	f, err := s.BuildCDFWithRand(rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return nil, err
+	}
+
+	return drawN(context.Background(), f, n)
+}
+
+// SampleN draws n indices from the weighted array, with replacement.
+// The CDF is built once and reused for every draw.
+func (s WeightedItemsFloat) SampleN(n int) ([]int, error) {
+	return s.SampleNContext(context.Background(), n)
+}
+
+// SampleNContext works like SampleN, but stops early if ctx is canceled,
+// returning the draws made so far along with ctx.Err().
+// The context is checked once every 1024 draws.
+func (s WeightedItemsFloat) SampleNContext(ctx context.Context, n int) ([]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	f, err := s.BuildCDF()
+	if err != nil {
+		return nil, err
+	}
+
+	return drawN(ctx, f, n)
+}
+
+// SampleNSeeded works like SampleN, but draws from
+// rand.New(rand.NewSource(seed)), so the same array and seed always
+// produce the same indices, whatever order the items are given in.
+// That makes the output safe to record as golden test data.
+//
+// The sequence depends on math/rand's seeded source, which Go keeps
+// stable, and on how this package maps random numbers to items, so
+// golden data may need regenerating if either of those ever changes.
+func (s WeightedItemsFloat) SampleNSeeded(n int, seed int64) ([]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	f, err := s.BuildCDFWithRand(rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return nil, err
+	}
+
+	return drawN(context.Background(), f, n)
+}
+
+// SampleCounts makes n draws from the weighted array, with replacement,
+// and returns how many times each index came up, without keeping the
+// draws themselves. Indices that never came up are absent, and the
+// counts always add up to n.
+func (s WeightedItems) SampleCounts(n int) (map[int]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	f, err := s.BuildCDF()
+	if err != nil {
+		return nil, err
+	}
+
+	return countN(f, n, len(s)), nil
+}
+
+// SampleCounts makes n draws from the weighted array, with replacement,
+// and returns how many times each index came up, without keeping the
+// draws themselves. Indices that never came up are absent, and the
+// counts always add up to n.
+func (s WeightedItemsFloat) SampleCounts(n int) (map[int]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	f, err := s.BuildCDF()
+	if err != nil {
+		return nil, err
+	}
+
+	return countN(f, n, len(s)), nil
+}
+
+// countN calls the sample function n times and tallies the
+// results, sizing the map for size distinct indices.
+func countN(f func() int, n, size int) map[int]int {
+	counts := make(map[int]int, size)
+	for i := 0; i < n; i++ {
+		counts[f()]++
+	}
+	return counts
+}
+
+// SampleSystematic draws n indices with systematic sampling: n equally
+// spaced points, shifted by a single random offset, are placed along the
+// cumulative distribution, and each returns the item it lands in. Each
+// index comes up within one of n times its probability, far closer than
+// n independent draws. The indices are returned in the sorted order the
+// sampler accumulates weights.
+func (s WeightedItems) SampleSystematic(n int) ([]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	c, err := s.build(newRand())
+	if err != nil {
+		return nil, err
+	}
+
+	return systematic(c.SampleAt, n, c.r.Float64())
+}
+
+// SampleSystematic draws n indices with systematic sampling: n equally
+// spaced points, shifted by a single random offset, are placed along the
+// cumulative distribution, and each returns the item it lands in. Each
+// index comes up within one of n times its probability, far closer than
+// n independent draws. The indices are returned in the sorted order the
+// sampler accumulates weights.
+func (s WeightedItemsFloat) SampleSystematic(n int) ([]int, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	c, err := s.build(newRand())
+	if err != nil {
+		return nil, err
+	}
+
+	return systematic(c.SampleAt, n, c.r.Float64())
+}
+
+// systematic returns the indices at the points (offset + k) / n
+// for k in [0, n), looked up with sampleAt.
+func systematic(sampleAt func(float64) (int, error), n int, offset float64) ([]int, error) {
+	// The largest point sampleAt accepts
+	last := math.Nextafter(1, 0)
+
+	out := make([]int, n)
+	for k := range out {
+		// An offset just below one can round the last point up to one
+		u := (offset + float64(k)) / float64(n)
+		if u > last {
+			u = last
+		}
+
+		index, err := sampleAt(u)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = index
+	}
+	return out, nil
+}
+
+// checkEvery is how many draws are made between context checks.
+const checkEvery = 1024
+
+// drawN calls the sample function n times and collects the results,
+// stopping early if ctx is canceled.
+func drawN(ctx context.Context, f func() int, n int) ([]int, error) {
+	out := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if i%checkEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return out, err
+			}
+		}
+		out = append(out, f())
+	}
+	return out, nil
+}
+
+// SampleWithoutReplacement draws k distinct indices from the weighted array.
+// Each draw picks an item with probability proportional to its weight among
+// the items not yet chosen, then removes it from the pool.
+func (s WeightedItems) SampleWithoutReplacement(k int) ([]int, error) {
+	if k < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	if k > len(s) {
+		return nil, ErrTooMany
+	}
+
+	total, err := s.total()
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the pool so items can be removed as they're drawn
+	pool := append(make(WeightedItems, 0, len(s)), s...)
+
+	r := newRand()
+	out := make([]int, 0, k)
+
+	for len(out) < k {
+		// Picking a random number in the range [1, remaining weight + 1)
+		num := r.Intn(total) + 1
+
+		// Walk the pool until the running sum reaches the number
+		i := 0
+		for sum := pool[0].Weight; sum < num; sum += pool[i].Weight {
+			i++
+		}
+
+		out = append(out, pool[i].Index)
+
+		// Remove the chosen item by swapping in the last one
+		total -= pool[i].Weight
+		pool[i] = pool[len(pool)-1]
+		pool = pool[:len(pool)-1]
+	}
+
+	return out, nil
+}
+
+// WeightedShuffle returns every original index in a random order where
+// each item's chance of coming first is proportional to its weight,
+// and likewise for the remaining items at each later position.
+func (s WeightedItems) WeightedShuffle() ([]int, error) {
+	return s.WeightedShuffleWithRand(newRand())
+}
+
+// WeightedShuffleWithRand works like WeightedShuffle, but draws
+// from the given random number generator.
+func (s WeightedItems) WeightedShuffleWithRand(r *rand.Rand) ([]int, error) {
+	if _, err := s.total(); err != nil {
+		return nil, err
+	}
+
+	// Give each item a key of log(u)/weight for a uniform u in (0, 1].
+	// Sorting by descending key is the same as repeatedly drawing
+	// and removing items in proportion to their weights.
+	keys := make([]float64, len(s))
+	order := make([]int, len(s))
+	for i, item := range s {
+		keys[i] = math.Log(1-r.Float64()) / float64(item.Weight)
+		order[i] = i
+	}
+
+	sort.Slice(order, func(a, b int) bool {
+		return keys[order[a]] > keys[order[b]]
+	})
+
+	out := make([]int, len(s))
+	for i, pos := range order {
+		out[i] = s[pos].Index
+	}
+
+	return out, nil
+}
+
+// maxCycleLength is the longest cycle ExhaustiveCycle will build.
+const maxCycleLength = math.MaxInt32
+
+// ExhaustiveCycle returns a cycle of indices, in random order, where each
+// item's index appears exactly as many times as its weight. The length of
+// the cycle is the total weight, so over a whole cycle the counts match
+// the weights exactly and only their order is random. Totals above
+// math.MaxInt32 are rejected with ErrWeightOverflow, since the cycle has
+// to fit in memory.
+func (s WeightedItems) ExhaustiveCycle() ([]int, error) {
+	return s.ExhaustiveCycleWithRand(newRand())
+}
+
+// ExhaustiveCycleWithRand works like ExhaustiveCycle, but draws
+// from the given random number generator.
+func (s WeightedItems) ExhaustiveCycleWithRand(r *rand.Rand) ([]int, error) {
+	total, err := s.total()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the cycle can be allocated
+	if total > maxCycleLength {
+		return nil, ErrWeightOverflow
+	}
+
+	cycle := make([]int, 0, total)
+	for _, item := range s {
+		for i := 0; i < item.Weight; i++ {
+			cycle = append(cycle, item.Index)
+		}
+	}
+
+	r.Shuffle(len(cycle), func(i, j int) {
+		cycle[i], cycle[j] = cycle[j], cycle[i]
+	})
+
+	return cycle, nil
+}
+
+// SampleInto fills buf with len(buf) draws from sampleFn,
+// without allocating, for reuse of one buffer in a tight loop.
+func SampleInto(buf []int, sampleFn func() int) {
+	for i := range buf {
+		buf[i] = sampleFn()
+	}
+}
+
+// SampleKDistinct draws k distinct indices with the same distribution as
+// SampleWithoutReplacement, using the Gumbel-top-k trick: each item's
+// log-weight gets independent Gumbel noise and the k largest keys win.
+// This takes O(n log k) time rather than O(k * n).
+func (s WeightedItems) SampleKDistinct(k int) ([]int, error) {
+	return s.SampleKDistinctWithRand(k, newRand())
+}
+
+// SampleKDistinctWithRand works like SampleKDistinct, but draws
+// from the given random number generator.
+func (s WeightedItems) SampleKDistinctWithRand(k int, r *rand.Rand) ([]int, error) {
+	if k < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	if k > len(s) {
+		return nil, ErrTooMany
+	}
+
+	if _, err := s.total(); err != nil {
+		return nil, err
+	}
+
+	weight := func(i int) float64 { return float64(s[i].Weight) }
+	index := func(i int) int { return s[i].Index }
+
+	return gumbelTopK(len(s), weight, index, k, r), nil
+}
+
+// gumbelTopK draws k distinct indices from n items without replacement,
+// in proportion to their weights, by keeping the k largest
+// log(weight) + Gumbel noise keys. The largest key comes first.
+func gumbelTopK(n int, weight func(int) float64, index func(int) int, k int, r *rand.Rand) []int {
+	// Keep the k largest keys seen so far, smallest on top
+	h := make(keyHeap, 0, k)
+	for i := 0; i < n; i++ {
+		gumbel := -math.Log(-math.Log(1 - r.Float64()))
+		key := keyedIndex{math.Log(weight(i)) + gumbel, index(i)}
+
+		if len(h) < k {
+			heap.Push(&h, key)
+		} else if k > 0 && key.key > h[0].key {
+			h[0] = key
+			heap.Fix(&h, 0)
+		}
+	}
+
+	// Pop smallest first, filling from the back so the largest key leads
+	out := make([]int, len(h))
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(&h).(keyedIndex).index
+	}
+
+	return out
+}
+
+// keyedIndex pairs an original index with a random sort key.
+type keyedIndex struct {
+	key   float64
+	index int
+}
+
+// keyHeap is a min-heap of keyed indices.
+type keyHeap []keyedIndex
+
+func (h keyHeap) Len() int           { return len(h) }
+func (h keyHeap) Less(i, j int) bool { return h[i].key < h[j].key }
+func (h keyHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *keyHeap) Push(x any) {
+	*h = append(*h, x.(keyedIndex))
+}
+
+func (h *keyHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Choose2 returns 0 with probability weightA/(weightA+weightB), otherwise 1,
+// for the common case of picking between two options without building a
+// weighted array. If r is nil, math/rand's shared generator is used.
+func Choose2(weightA, weightB int, r *rand.Rand) (int, error) {
+	// Make sure both options have positive weight
+	if weightA <= 0 || weightB <= 0 {
+		return 0, ErrNonPositiveWeight
+	}
+
+	// Make sure the total can't wrap around
+	if weightA > math.MaxInt-weightB {
+		return 0, ErrWeightOverflow
+	}
+
+	intn := rand.Intn
+	if r != nil {
+		intn = r.Intn
+	}
+
+	if intn(weightA+weightB) < weightA {
+		return 0, nil
+	}
+	return 1, nil
+}
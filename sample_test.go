@@ -0,0 +1,437 @@
+package stairs
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestSampleN checks that SampleN returns the requested
+// number of indices, all within range.
+func TestSampleN(t *testing.T) {
+	w := buildWeightedArray()
+
+	out, err := w.SampleN(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 50 {
+		t.Fatalf("got %d samples, want 50", len(out))
+	}
+
+	for _, index := range out {
+		if index < 0 || index >= len(w) {
+			t.Fail()
+		}
+	}
+}
+
+// TestSampleNZero checks that asking for no samples
+// returns an empty, non-nil slice.
+func TestSampleNZero(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	out, err := w.SampleN(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out == nil || len(out) != 0 {
+		t.Fail()
+	}
+}
+
+// TestSampleNNegative checks that a negative sample count is rejected.
+func TestSampleNNegative(t *testing.T) {
+	w := buildWeightedArray()
+
+	if _, err := w.SampleN(-1); !errors.Is(err, ErrNegativeCount) {
+		t.Fail()
+	}
+}
+
+// TestSampleWithoutReplacement checks that every index
+// drawn without replacement is distinct.
+func TestSampleWithoutReplacement(t *testing.T) {
+	w := WeightedItems{{1, 0}, {2, 1}, {3, 2}, {4, 3}, {5, 4}}
+
+	for i := 0; i < 100; i++ {
+		out, err := w.SampleWithoutReplacement(len(w))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		seen := make(map[int]bool)
+		for _, index := range out {
+			if seen[index] || index < 0 || index >= len(w) {
+				t.Fatalf("invalid draw %v", out)
+			}
+			seen[index] = true
+		}
+	}
+}
+
+// TestSampleWithoutReplacementTooMany checks that asking for
+// more items than exist is rejected.
+func TestSampleWithoutReplacementTooMany(t *testing.T) {
+	w := buildWeightedArray()
+
+	if _, err := w.SampleWithoutReplacement(len(w) + 1); !errors.Is(err, ErrTooMany) {
+		t.Fail()
+	}
+}
+
+// TestSampleWithoutReplacementOverflow checks that weights whose
+// sum exceeds the int range are rejected instead of wrapping.
+func TestSampleWithoutReplacementOverflow(t *testing.T) {
+	w := WeightedItems{{math.MaxInt - 1, 0}, {math.MaxInt - 2, 1}}
+
+	if _, err := w.SampleWithoutReplacement(1); !errors.Is(err, ErrWeightOverflow) {
+		t.Fail()
+	}
+}
+
+// TestWeightedShuffle checks that a shuffle contains every
+// index once and tends to put the heaviest item first.
+func TestWeightedShuffle(t *testing.T) {
+	w := WeightedItems{{1, 0}, {1, 1}, {50, 2}, {1, 3}}
+	r := rand.New(rand.NewSource(3))
+
+	first := 0
+	for i := 0; i < 500; i++ {
+		out, err := w.WeightedShuffleWithRand(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		seen := make(map[int]bool)
+		for _, index := range out {
+			seen[index] = true
+		}
+		if len(out) != len(w) || len(seen) != len(w) {
+			t.Fatalf("shuffle %v isn't a permutation", out)
+		}
+
+		if out[0] == 2 {
+			first++
+		}
+	}
+
+	if first < 400 {
+		t.Errorf("heaviest item first %d times out of 500", first)
+	}
+}
+
+// TestWeightedShuffleEmpty checks that an empty array is rejected.
+func TestWeightedShuffleEmpty(t *testing.T) {
+	var w WeightedItems
+
+	if _, err := w.WeightedShuffle(); !errors.Is(err, ErrEmpty) {
+		t.Fail()
+	}
+}
+
+// TestSampleNContextCanceled checks that a canceled context
+// stops sampling and reports the cancellation.
+func TestSampleNContextCanceled(t *testing.T) {
+	w := buildWeightedArray()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out, err := w.SampleNContext(ctx, 1000000)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+
+	if len(out) >= 1000000 {
+		t.Error("sampling wasn't stopped early")
+	}
+}
+
+// TestSampleInto checks that the whole buffer
+// is filled with indices in range.
+func TestSampleInto(t *testing.T) {
+	w := buildWeightedArray()
+
+	f, err := w.BuildCDF()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]int, 64)
+	for i := range buf {
+		buf[i] = -1
+	}
+
+	SampleInto(buf, f)
+
+	for _, index := range buf {
+		if index < 0 || index >= len(w) {
+			t.Fail()
+		}
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { SampleInto(buf, f) }); allocs != 0 {
+		t.Errorf("got %v allocations per call, want 0", allocs)
+	}
+}
+
+// BenchmarkSampleInto measures filling a reused buffer.
+func BenchmarkSampleInto(b *testing.B) {
+	f, err := buildWeightedArray().BuildCDF()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	buf := make([]int, 1024)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SampleInto(buf, f)
+	}
+}
+
+// TestSampleKDistinct checks that the drawn indices are distinct
+// and follow the weights for the first pick.
+func TestSampleKDistinct(t *testing.T) {
+	w := WeightedItems{{1, 0}, {2, 1}, {3, 2}, {4, 3}}
+	r := rand.New(rand.NewSource(8))
+
+	const draws = 20000
+	first := make([]int, len(w))
+	for i := 0; i < draws; i++ {
+		out, err := w.SampleKDistinctWithRand(2, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(out) != 2 || out[0] == out[1] {
+			t.Fatalf("got %v", out)
+		}
+		first[out[0]]++
+	}
+
+	// The first pick is an ordinary weighted draw
+	for i, count := range first {
+		want := float64(w[i].Weight) / 10
+		if observed := float64(count) / draws; math.Abs(observed-want) > 0.015 {
+			t.Errorf("index %d first %v of the time, want %v", i, observed, want)
+		}
+	}
+}
+
+// TestSampleKDistinctBounds checks the edge cases for k.
+func TestSampleKDistinctBounds(t *testing.T) {
+	w := buildWeightedArray()
+
+	if out, err := w.SampleKDistinct(0); err != nil || len(out) != 0 {
+		t.Errorf("got %v, %v for k = 0", out, err)
+	}
+
+	if out, err := w.SampleKDistinct(len(w)); err != nil || len(out) != len(w) {
+		t.Errorf("got %v, %v for k = len", out, err)
+	}
+
+	if _, err := w.SampleKDistinct(len(w) + 1); !errors.Is(err, ErrTooMany) {
+		t.Error("accepted k larger than the array")
+	}
+}
+
+// TestChoose2 checks that two options are picked
+// in proportion to their weights.
+func TestChoose2(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+
+	const draws = 20000
+	count := 0
+	for i := 0; i < draws; i++ {
+		choice, err := Choose2(1, 3, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count += choice
+	}
+
+	if observed := float64(count) / draws; math.Abs(observed-0.75) > 0.015 {
+		t.Errorf("second option picked %v of the time, want 0.75", observed)
+	}
+
+	if _, err := Choose2(0, 3, nil); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Error("accepted a zero weight")
+	}
+}
+
+// TestSampleNSeeded checks the seeded draws against recorded output,
+// and that the order of the input doesn't change them.
+func TestSampleNSeeded(t *testing.T) {
+	want := []int{1, 2, 2, 2, 2, 1, 2, 0, 0, 2, 1, 2}
+
+	w := buildWeightedArray()
+	reversed := WeightedItems{w[2], w[1], w[0]}
+
+	for _, s := range []WeightedItems{w, reversed} {
+		got, err := s.SampleNSeeded(len(want), 42)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		}
+	}
+
+	if _, err := w.SampleNSeeded(-1, 42); !errors.Is(err, ErrNegativeCount) {
+		t.Errorf("got %v, want ErrNegativeCount", err)
+	}
+}
+
+// TestSampleNSeededFloat checks the seeded draws against recorded output.
+func TestSampleNSeededFloat(t *testing.T) {
+	want := []int{1, 0, 2, 1, 0, 1, 2, 1, 1, 2, 2, 1}
+
+	got, err := buildWeightedFloatArray().SampleNSeeded(len(want), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+// TestExhaustiveCycle checks that each index appears exactly
+// as many times as its weight, in a shuffled order.
+func TestExhaustiveCycle(t *testing.T) {
+	w := buildWeightedArray()
+
+	cycle, err := w.ExhaustiveCycleWithRand(rand.New(rand.NewSource(4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cycle) != 8 {
+		t.Fatalf("got %d entries, want 8", len(cycle))
+	}
+
+	counts := make(map[int]int)
+	for _, index := range cycle {
+		counts[index]++
+	}
+	for _, item := range w {
+		if counts[item.Index] != item.Weight {
+			t.Errorf("got %v", counts)
+		}
+	}
+
+	if _, err := (WeightedItems{}).ExhaustiveCycle(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+
+	huge := WeightedItems{{math.MaxInt / 2, 0}, {1, 1}}
+	if _, err := huge.ExhaustiveCycle(); !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("got %v for a cycle too long to allocate, want ErrWeightOverflow", err)
+	}
+}
+
+// TestSampleCounts checks that the counts add up
+// to n and follow the weights.
+func TestSampleCounts(t *testing.T) {
+	counts, err := buildWeightedArray().SampleCounts(8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := 0
+	for _, n := range counts {
+		sum += n
+	}
+	if sum != 8000 {
+		t.Errorf("got %d draws, want 8000", sum)
+	}
+
+	// Index 2 holds 5 of the 8 units of weight
+	if counts[2] < 4600 || counts[2] > 5400 {
+		t.Errorf("got %v", counts)
+	}
+
+	if _, err := buildWeightedFloatArray().SampleCounts(-1); !errors.Is(err, ErrNegativeCount) {
+		t.Errorf("got %v, want ErrNegativeCount", err)
+	}
+}
+
+// TestSampleSystematic checks that every count is within
+// one of its expected value.
+func TestSampleSystematic(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		out, err := buildWeightedArray().SampleSystematic(80)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counts := make([]int, 3)
+		for _, index := range out {
+			counts[index]++
+		}
+
+		// The weights 1, 2 and 5 out of 8 expect 10, 20 and 50
+		if counts[0] < 9 || counts[0] > 11 || counts[1] < 19 || counts[1] > 21 || counts[2] < 49 || counts[2] > 51 {
+			t.Fatalf("got %v", counts)
+		}
+	}
+
+	if _, err := buildWeightedFloatArray().SampleSystematic(-1); !errors.Is(err, ErrNegativeCount) {
+		t.Errorf("got %v, want ErrNegativeCount", err)
+	}
+}
+
+// TestSystematicOffset checks the points for a fixed offset.
+func TestSystematicOffset(t *testing.T) {
+	c, err := buildWeightedFloatArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The points 0.125, 0.375, 0.625 and 0.875 fall in index 0's
+	// first 15%, index 1's next 24% and index 2's remaining 61%
+	out, err := systematic(c.SampleAt, 4, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{0, 1, 2, 2}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Fatalf("got %v, want %v", out, want)
+		}
+	}
+}
+
+// TestSystematicLargestOffset checks that an offset just below one,
+// which can round the last point up to one, still lands in the last item.
+func TestSystematicLargestOffset(t *testing.T) {
+	c, err := buildWeightedArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := buildWeightedFloatArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offset := math.Nextafter(1, 0)
+	for _, sampleAt := range []func(float64) (int, error){c.SampleAt, f.SampleAt} {
+		for _, n := range []int{2, 3, 1000} {
+			out, err := systematic(sampleAt, n, offset)
+			if err != nil {
+				t.Fatalf("n = %d: %v", n, err)
+			}
+			if out[n-1] != 2 {
+				t.Errorf("n = %d: got %d for the last point, want 2", n, out[n-1])
+			}
+		}
+	}
+}
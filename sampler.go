@@ -0,0 +1,82 @@
+package stairs
+
+// Sampler randomly selects items of type T according to their weights,
+// returning the items themselves rather than indices.
+type Sampler[T any] struct {
+	items []T
+	cdf   func() int
+}
+
+// NewSampler builds a Sampler where weights[i] is the weight of items[i].
+// The items are copied, so later changes to the slice don't affect sampling.
+func NewSampler[T any](items []T, weights []int) (*Sampler[T], error) {
+	if len(items) != len(weights) {
+		return nil, ErrLengthMismatch
+	}
+
+	w := make(WeightedItems, len(weights))
+	for i, weight := range weights {
+		w[i] = WeightedItem{weight, i}
+	}
+
+	cdf, err := w.BuildCDF()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sampler[T]{items: append([]T(nil), items...), cdf: cdf}, nil
+}
+
+// Sample returns a random item, chosen according to the weights.
+func (s *Sampler[T]) Sample() T {
+	return s.items[s.cdf()]
+}
+
+// Number is any integer or floating-point type that can be used as a weight.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// NewWeightedSampler builds a sampler from weights of any numeric type,
+// where weights[i] is the weight of index i. Integer weights use the
+// integer CDF, and must fit in an int; floating-point weights use the
+// floating-point CDF.
+func NewWeightedSampler[W Number](weights []W) (func() int, error) {
+	if !isInteger[W]() {
+		w := make(WeightedItemsFloat, len(weights))
+		for i, weight := range weights {
+			w[i] = WeightedItemFloat{float64(weight), i}
+		}
+		return w.BuildCDF()
+	}
+
+	w := make(WeightedItems, len(weights))
+	for i, weight := range weights {
+		// Make sure the weight survives the conversion to int
+		if weight > 0 && (int(weight) <= 0 || W(int(weight)) != weight) {
+			return nil, ErrWeightOverflow
+		}
+		w[i] = WeightedItem{int(weight), i}
+	}
+	return w.BuildCDF()
+}
+
+// isInteger reports whether W is an integer type.
+func isInteger[W Number]() bool {
+	var half W = 1
+	half /= 2
+	return half == 0
+}
+
+// BuildFromValues builds a sampler over values where weights[i] is the
+// weight of values[i], returning values directly. It fails if the two
+// slices differ in length.
+func BuildFromValues[T any](values []T, weights []int) (func() T, error) {
+	s, err := NewSampler(values, weights)
+	if err != nil {
+		return nil, err
+	}
+	return s.Sample, nil
+}
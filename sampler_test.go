@@ -0,0 +1,117 @@
+package stairs
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestSampler checks that a Sampler only returns
+// items from the slice it was built with.
+func TestSampler(t *testing.T) {
+	items := []string{"str", "str2", "str3"}
+
+	s, err := NewSampler(items, []int{1, 2, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		switch s.Sample() {
+		case "str", "str2", "str3":
+		default:
+			t.Fail()
+		}
+	}
+}
+
+// TestSamplerSingle checks that a Sampler with one item always returns it.
+func TestSamplerSingle(t *testing.T) {
+	s, err := NewSampler([]string{"only"}, []int{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if s.Sample() != "only" {
+			t.Fail()
+		}
+	}
+}
+
+// TestSamplerLengthMismatch checks that items and weights
+// of different lengths are rejected.
+func TestSamplerLengthMismatch(t *testing.T) {
+	_, err := NewSampler([]string{"a", "b"}, []int{1})
+
+	if !errors.Is(err, ErrLengthMismatch) {
+		t.Fail()
+	}
+}
+
+// TestNewWeightedSampler checks that samplers can be built
+// from several numeric weight types.
+func TestNewWeightedSampler(t *testing.T) {
+	fns := make([]func() int, 0, 3)
+
+	f, err := NewWeightedSampler([]uint8{1, 2, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fns = append(fns, f)
+
+	f, err = NewWeightedSampler([]float32{1.5, 2.33, 5.8999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fns = append(fns, f)
+
+	f, err = NewWeightedSampler([]int64{4, 4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fns = append(fns, f)
+
+	for _, f := range fns {
+		for i := 0; i < 100; i++ {
+			if index := f(); index < 0 || index >= 3 {
+				t.Fail()
+			}
+		}
+	}
+}
+
+// TestNewWeightedSamplerInvalid checks that non-positive
+// and out-of-range weights are rejected.
+func TestNewWeightedSamplerInvalid(t *testing.T) {
+	if _, err := NewWeightedSampler([]int{3, 0}); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Error("accepted a zero weight")
+	}
+
+	if _, err := NewWeightedSampler([]float64{}); !errors.Is(err, ErrEmpty) {
+		t.Error("accepted no weights")
+	}
+
+	if _, err := NewWeightedSampler([]uint64{1, math.MaxUint64}); !errors.Is(err, ErrWeightOverflow) {
+		t.Error("accepted a weight too large for an int")
+	}
+}
+
+// TestBuildFromValues checks that values are returned directly
+// and that mismatched lengths are rejected.
+func TestBuildFromValues(t *testing.T) {
+	f, err := BuildFromValues([]string{"a", "b", "c"}, []int{1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if v := f(); v != "a" && v != "b" && v != "c" {
+			t.Fatalf("got %q", v)
+		}
+	}
+
+	if _, err := BuildFromValues([]string{"a"}, []int{1, 2}); !errors.Is(err, ErrLengthMismatch) {
+		t.Error("accepted slices of different lengths")
+	}
+}
@@ -0,0 +1,93 @@
+package stairs
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SamplerPool hands out CDFs whose buffers are reused from one build to
+// the next, for callers that build many small, short-lived distributions.
+// Once the pool is warm, a build that fits in a returned CDF's capacity
+// doesn't allocate.
+//
+// The zero value is ready to use. A SamplerPool is safe for concurrent
+// use, but each PooledCDF it hands out is not.
+type SamplerPool struct {
+	pool sync.Pool
+}
+
+// PooledCDF is a CDF handed out by a SamplerPool, along with
+// the scratch space used to build it.
+type PooledCDF struct {
+	CDF
+	// seen is reused to check for duplicate indices
+	seen map[int]struct{}
+}
+
+// Get builds a CDF from items, like Build, reusing a CDF returned to the
+// pool with Put if there is one. The CDF keeps its generator between
+// uses, so its sequence carries on rather than starting over.
+func (p *SamplerPool) Get(items WeightedItems) (*PooledCDF, error) {
+	c, _ := p.pool.Get().(*PooledCDF)
+	if c == nil {
+		seed := time.Now().UnixNano()
+		c = &PooledCDF{
+			CDF:  CDF{r: rand.New(rand.NewSource(seed)), seed: seed},
+			seen: make(map[int]struct{}, len(items)),
+		}
+	}
+
+	if err := c.build(items); err != nil {
+		p.Put(c)
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Put returns c to the pool for a later Get to reuse.
+// c mustn't be used after it's put back.
+func (p *SamplerPool) Put(c *PooledCDF) {
+	c.items = c.items[:0]
+	p.pool.Put(c)
+}
+
+// build validates, copies, sorts and accumulates items into c's buffers.
+func (c *PooledCDF) build(items WeightedItems) error {
+	// Reject empty arrays
+	if len(items) <= 0 {
+		return ErrEmpty
+	}
+
+	clear(c.seen)
+	for _, item := range items {
+		// Make sure all items have positive weight
+		if item.Weight <= 0 {
+			return ErrNonPositiveWeight
+		}
+
+		// Reject items that point to the same index
+		if _, ok := c.seen[item.Index]; ok {
+			return ErrDuplicateIndex
+		}
+		c.seen[item.Index] = struct{}{}
+	}
+
+	c.items = append(c.items[:0], items...)
+
+	// Sort ascending by weight, then by index, the same order as Less
+	slices.SortFunc(c.items, compareItems)
+
+	return c.items.accumulate()
+}
+
+// compareItems orders items like WeightedItems.Less.
+func compareItems(a, b WeightedItem) int {
+	if a.Weight != b.Weight {
+		return cmp.Compare(a.Weight, b.Weight)
+	}
+	return cmp.Compare(a.Index, b.Index)
+}
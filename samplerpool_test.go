@@ -0,0 +1,87 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSamplerPool checks that pooled CDFs sample like
+// built ones and that bad arrays are rejected.
+func TestSamplerPool(t *testing.T) {
+	var p SamplerPool
+
+	c, err := p.Get(WeightedItems{{5, 2}, {2, 1}, {1, 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := buildWeightedArray().cumulative()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if c.items[i] != want[i] {
+			t.Fatalf("got %v, want %v", c.items, want)
+		}
+	}
+	p.Put(c)
+
+	if _, err := p.Get(WeightedItems{{1, 0}, {1, 0}}); !errors.Is(err, ErrDuplicateIndex) {
+		t.Errorf("got %v, want ErrDuplicateIndex", err)
+	}
+	if _, err := p.Get(nil); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+
+	// A reused CDF mustn't remember the last build's indices
+	c, err = p.Get(WeightedItems{{3, 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Sample() != 0 {
+		t.Error("got the wrong index")
+	}
+	p.Put(c)
+}
+
+// TestSamplerPoolAllocs checks that rebuilding from a warm pool
+// doesn't allocate.
+func TestSamplerPoolAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+
+	var p SamplerPool
+	w := buildWeightedArray()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		c, err := p.Get(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Sample()
+		p.Put(c)
+	})
+
+	// Allow for the pool occasionally being emptied by the garbage collector
+	if allocs > 0.1 {
+		t.Errorf("got %v allocations per build, want 0", allocs)
+	}
+}
+
+// BenchmarkSamplerPool measures building and sampling
+// a small CDF from a warm pool.
+func BenchmarkSamplerPool(b *testing.B) {
+	var p SamplerPool
+	w := buildWeightedArray()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c, err := p.Get(w)
+		if err != nil {
+			b.Fatal(err)
+		}
+		c.Sample()
+		p.Put(c)
+	}
+}
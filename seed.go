@@ -0,0 +1,29 @@
+package stairs
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// BuildCDFSeedString works like BuildCDFWithRand with a generator seeded
+// from a hash of seed, so a readable name such as "experiment-42" can
+// stand in for a numeric seed in configuration. The same string always
+// produces the same sequence for the same array.
+func (s WeightedItems) BuildCDFSeedString(seed string) (func() int, error) {
+	return s.BuildCDFWithRand(rand.New(rand.NewSource(seedFromString(seed))))
+}
+
+// BuildCDFSeedString works like BuildCDFWithRand with a generator seeded
+// from a hash of seed, so a readable name such as "experiment-42" can
+// stand in for a numeric seed in configuration. The same string always
+// produces the same sequence for the same array.
+func (s WeightedItemsFloat) BuildCDFSeedString(seed string) (func() int, error) {
+	return s.BuildCDFWithRand(rand.New(rand.NewSource(seedFromString(seed))))
+}
+
+// seedFromString hashes s with 64-bit FNV-1a into a seed.
+func seedFromString(s string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return int64(h.Sum64())
+}
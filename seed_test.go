@@ -0,0 +1,39 @@
+package stairs
+
+import "testing"
+
+// TestSeedFromString checks that the hash is FNV-1a and
+// doesn't change between releases.
+func TestSeedFromString(t *testing.T) {
+	// The 64-bit FNV-1a offset basis, since nothing is hashed
+	if got := uint64(seedFromString("")); got != 0xcbf29ce484222325 {
+		t.Errorf("got %#x, want the FNV-1a offset basis", got)
+	}
+
+	if seedFromString("experiment-42") == seedFromString("experiment-43") {
+		t.Error("different strings gave the same seed")
+	}
+}
+
+// TestBuildSeedString checks that the same string
+// gives the same sequence.
+func TestBuildSeedString(t *testing.T) {
+	f, err := buildWeightedArray().BuildCDFSeedString("experiment-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := buildWeightedArray().BuildCDFSeedString("experiment-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if f() != g() {
+			t.Fatal("same string gave different sequences")
+		}
+	}
+
+	if _, err := buildWeightedFloatArray().BuildCDFSeedString("experiment-42"); err != nil {
+		t.Error(err)
+	}
+}
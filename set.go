@@ -0,0 +1,108 @@
+package stairs
+
+import "math/rand"
+
+// WeightedSet is a set of keys, each with a weight, that can be sampled
+// while keys are added and removed. It's a DynamicSampler that gives
+// each key a stable index of its own. Adding, removing and sampling each
+// take O(log n), and checking membership takes O(1).
+//
+// The zero value is ready to use and seeds its own generator.
+// A WeightedSet is not safe for concurrent use.
+type WeightedSet[K comparable] struct {
+	d *DynamicSampler
+	// ids maps keys to their index in d, and keys maps them back
+	ids  map[K]int
+	keys []K
+	// free holds indices left behind by removed keys
+	free []int
+}
+
+// NewWeightedSet creates an empty WeightedSet that draws from r.
+// If r is nil, a generator seeded from the current time is used.
+func NewWeightedSet[K comparable](r *rand.Rand) *WeightedSet[K] {
+	return &WeightedSet[K]{d: NewDynamicSampler(r)}
+}
+
+// Add adds key to the set with the given weight, or
+// changes its weight if it's already in the set.
+func (s *WeightedSet[K]) Add(key K, weight int) error {
+	if id, ok := s.ids[key]; ok {
+		return s.d.UpdateWeight(id, weight)
+	}
+
+	if s.d == nil {
+		s.d = NewDynamicSampler(nil)
+	}
+
+	// Reuse a removed key's index if there is one
+	var id int
+	if n := len(s.free); n > 0 {
+		id = s.free[n-1]
+	} else {
+		id = len(s.keys)
+	}
+
+	if err := s.d.AddItem(weight, id); err != nil {
+		return err
+	}
+
+	if id == len(s.keys) {
+		s.keys = append(s.keys, key)
+	} else {
+		s.free = s.free[:len(s.free)-1]
+		s.keys[id] = key
+	}
+
+	if s.ids == nil {
+		s.ids = make(map[K]int)
+	}
+	s.ids[key] = id
+
+	return nil
+}
+
+// Remove removes key from the set. Removing a key
+// that isn't in the set does nothing.
+func (s *WeightedSet[K]) Remove(key K) {
+	id, ok := s.ids[key]
+	if !ok {
+		return
+	}
+
+	s.d.RemoveItem(id)
+	delete(s.ids, key)
+
+	// Drop the key so the set doesn't keep it alive
+	var zero K
+	s.keys[id] = zero
+	s.free = append(s.free, id)
+}
+
+// Contains reports whether key is in the set.
+func (s *WeightedSet[K]) Contains(key K) bool {
+	_, ok := s.ids[key]
+	return ok
+}
+
+// Len returns the number of keys in the set.
+func (s *WeightedSet[K]) Len() int {
+	return len(s.ids)
+}
+
+// Sample returns a random key, chosen according to the current weights.
+func (s *WeightedSet[K]) Sample() (K, error) {
+	// Nothing to pick from
+	if s.d == nil {
+		var zero K
+		return zero, ErrEmpty
+	}
+
+	id, err := s.d.Sample()
+	if err != nil {
+		var zero K
+		return zero, err
+	}
+
+	return s.keys[id], nil
+}
@@ -0,0 +1,70 @@
+package stairs
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// TestWeightedSet checks membership and that removed
+// keys are never sampled.
+func TestWeightedSet(t *testing.T) {
+	s := NewWeightedSet[string](rand.New(rand.NewSource(6)))
+
+	for key, weight := range map[string]int{"a": 1, "b": 2, "c": 5} {
+		if err := s.Add(key, weight); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s.Remove("c")
+	s.Remove("missing")
+
+	if s.Contains("c") || !s.Contains("a") || s.Len() != 2 {
+		t.Fatalf("got the wrong members")
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		key, err := s.Sample()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[key]++
+	}
+
+	// b holds 2 of the remaining 3 units of weight
+	if counts["c"] != 0 || counts["b"] < 1800 || counts["b"] > 2200 {
+		t.Errorf("got %v", counts)
+	}
+}
+
+// TestWeightedSetUpdate checks that adding an existing key changes
+// its weight, and that an emptied set can't be sampled.
+func TestWeightedSetUpdate(t *testing.T) {
+	var s WeightedSet[int]
+
+	if err := s.Add(7, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(7, 1); err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 1 || s.d.Total() != 1 {
+		t.Errorf("got %d keys with total %d, want 1 and 1", s.Len(), s.d.Total())
+	}
+
+	if err := s.Add(8, 0); !errors.Is(err, ErrNonPositiveWeight) || s.Contains(8) {
+		t.Errorf("got %v, want ErrNonPositiveWeight and 8 left out", err)
+	}
+
+	s.Remove(7)
+	if _, err := s.Sample(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+
+	// Removed indices are reused
+	if err := s.Add(9, 2); err != nil || len(s.keys) != 1 {
+		t.Errorf("got %v with %d indices, want 1", err, len(s.keys))
+	}
+}
@@ -0,0 +1,44 @@
+package stairs
+
+import "sort"
+
+// Shard splits the array into n non-empty shards of roughly equal total
+// weight, each of which can be built into its own CDF. Items are taken
+// heaviest first and each goes to the shard with the smallest total so
+// far, which keeps the heaviest shard within 4/3 of the best possible
+// split. n must be between 1 and the number of items.
+func (s WeightedItems) Shard(n int) ([]WeightedItems, error) {
+	if _, err := s.total(); err != nil {
+		return nil, err
+	}
+
+	if n < 1 || n > len(s) {
+		return nil, ErrOutOfRange
+	}
+
+	// Sort a copy heaviest first, leaving the caller's order alone
+	sorted := append(make(WeightedItems, 0, len(s)), s...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Weight != sorted[j].Weight {
+			return sorted[i].Weight > sorted[j].Weight
+		}
+		return sorted[i].Index < sorted[j].Index
+	})
+
+	shards := make([]WeightedItems, n)
+	totals := make([]int, n)
+	for _, item := range sorted {
+		// Give the item to the lightest shard, the first one on ties
+		lightest := 0
+		for i := 1; i < n; i++ {
+			if totals[i] < totals[lightest] {
+				lightest = i
+			}
+		}
+
+		shards[lightest] = append(shards[lightest], item)
+		totals[lightest] += item.Weight
+	}
+
+	return shards, nil
+}
@@ -0,0 +1,53 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestShard checks that every item lands in exactly
+// one shard and the totals are balanced.
+func TestShard(t *testing.T) {
+	w := WeightedItems{{2, 0}, {4, 1}, {3, 2}, {2, 3}, {3, 4}, {2, 5}}
+
+	shards, err := w.Shard(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[int]bool)
+	for _, shard := range shards {
+		total, err := shard.TotalWeight()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The weights sum to 16, which splits evenly into 8s
+		if total != 8 {
+			t.Errorf("got shards %v", shards)
+		}
+
+		for _, item := range shard {
+			if seen[item.Index] {
+				t.Errorf("index %d is in two shards", item.Index)
+			}
+			seen[item.Index] = true
+		}
+	}
+
+	if len(seen) != len(w) {
+		t.Errorf("got %d items, want %d", len(seen), len(w))
+	}
+}
+
+// TestShardInvalid checks that shard counts outside
+// 1 to the number of items are rejected.
+func TestShardInvalid(t *testing.T) {
+	w := buildWeightedArray()
+
+	for _, n := range []int{0, len(w) + 1} {
+		if _, err := w.Shard(n); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("n = %d: got %v, want ErrOutOfRange", n, err)
+		}
+	}
+}
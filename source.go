@@ -0,0 +1,30 @@
+package stairs
+
+// WeightSource supplies weights from wherever they're kept, such as a
+// database, a key-value store or a static slice. Weights returns the
+// current weights, where entry i is the weight of index i.
+type WeightSource interface {
+	Weights() ([]int, error)
+}
+
+// BuildCDFFromSource reads the current weights from src and builds a
+// sampler from them, like BuildFromSlices. The weights are read once;
+// call it again to pick up changes in the source. Errors from the
+// source are returned unchanged.
+func BuildCDFFromSource(src WeightSource) (func() int, error) {
+	weights, err := src.Weights()
+	if err != nil {
+		return nil, err
+	}
+	return BuildFromSlices(weights)
+}
+
+// SliceSource is a WeightSource over a fixed slice of weights.
+type SliceSource []int
+
+var _ WeightSource = SliceSource(nil)
+
+// Weights returns a copy of the slice.
+func (s SliceSource) Weights() ([]int, error) {
+	return append([]int(nil), s...), nil
+}
@@ -0,0 +1,35 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// failingSource is a WeightSource that can't be read.
+type failingSource struct{}
+
+func (failingSource) Weights() ([]int, error) {
+	return nil, errors.New("unreachable")
+}
+
+// TestBuildCDFFromSource checks building from a slice source
+// and that source errors are passed through.
+func TestBuildCDFFromSource(t *testing.T) {
+	if _, err := BuildCDFFromSource(SliceSource{0, 3}); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Errorf("got %v, want ErrNonPositiveWeight", err)
+	}
+
+	f, err := BuildCDFFromSource(SliceSource{1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if index := f(); index != 0 && index != 1 {
+			t.Fatalf("got index %d", index)
+		}
+	}
+
+	if _, err := BuildCDFFromSource(failingSource{}); err == nil || err.Error() != "unreachable" {
+		t.Errorf("got %v, want the source's error", err)
+	}
+}
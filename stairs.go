@@ -10,18 +10,18 @@ import (
 )
 
 // EPSILON is an arbitrarily small floating-point
-// number used for equality comparison when
-// searching through a floating-point CDF.
+// number used as the tolerance when checking that
+// floating-point values add up as expected.
 const EPSILON = 0.00001
 
 // WeightedItem contains the weight for the item
 // and the index it represents in the original array.
 type WeightedItem struct {
 	// The relative weight assigned to the item
-	Weight int
+	Weight int `json:"weight"`
 	// Index is the location in the original array
 	// for the item
-	Index int
+	Index int `json:"index"`
 }
 
 // WeightedItems is an array of WeightedItem interfaces.
@@ -32,22 +32,40 @@ type WeightedItems []WeightedItem
 // original array.
 type WeightedItemFloat struct {
 	// The relative weight assigned to the item
-	Weight float64
+	Weight float64 `json:"weight"`
 	// Index is the location in the original array
 	// for the item
-	Index int
+	Index int `json:"index"`
 }
 
 // WeightedItemsFloat is an array of WeightedItemFloat interfaces.
 type WeightedItemsFloat []WeightedItemFloat
 
+// Distribution is satisfied by both WeightedItems and WeightedItemsFloat,
+// so code can accept either kind of weighted array.
+type Distribution interface {
+	// BuildCDF returns a function that selects random indices
+	BuildCDF() (func() int, error)
+	// Len returns the number of items
+	Len() int
+}
+
+var (
+	_ Distribution = WeightedItems(nil)
+	_ Distribution = WeightedItemsFloat(nil)
+)
+
 // Sort interface implementation
-// sort.Sort will sort by weight ascending
+// sort.Sort will sort by weight ascending, then by index
 func (s WeightedItems) Len() int {
 	return len(s)
 }
 
 func (s WeightedItems) Less(i, j int) bool {
+	// Break ties by index so equal weights always sort the same way
+	if s[i].Weight == s[j].Weight {
+		return s[i].Index < s[j].Index
+	}
 	return s[i].Weight < s[j].Weight
 }
 
@@ -58,12 +76,16 @@ func (s WeightedItems) Swap(i, j int) {
 }
 
 // Sort interface implementation
-// sort.Sort will sort by weight ascending
+// sort.Sort will sort by weight ascending, then by index
 func (s WeightedItemsFloat) Len() int {
 	return len(s)
 }
 
 func (s WeightedItemsFloat) Less(i, j int) bool {
+	// Break ties by index so equal weights always sort the same way
+	if s[i].Weight == s[j].Weight {
+		return s[i].Index < s[j].Index
+	}
 	return s[i].Weight < s[j].Weight
 }
 
@@ -73,154 +95,351 @@ func (s WeightedItemsFloat) Swap(i, j int) {
 	s[j] = temp
 }
 
-const tooShortErr = "Array of items must be longer than 0."
-const zeroWeightErr = "All items must have a positive weight."
+// hasDuplicateIndex reports whether any two of the n
+// indices returned by index are the same.
+func hasDuplicateIndex(n int, index func(int) int) bool {
+	seen := make(map[int]struct{}, n)
+	for i := 0; i < n; i++ {
+		if _, ok := seen[index(i)]; ok {
+			return true
+		}
+		seen[index(i)] = struct{}{}
+	}
+	return false
+}
 
-// BuildCDF converts a weighted array into a function that will return
-// random elements from it, when called.
-func (s WeightedItems) BuildCDF() (func() int, error) {
+// kahanSum adds up floating-point numbers with Kahan summation,
+// carrying the rounding error of each addition into the next.
+type kahanSum struct {
+	sum  float64
+	comp float64
+}
+
+func (k *kahanSum) add(x float64) {
+	y := x - k.comp
+	t := k.sum + y
+	k.comp = (t - k.sum) - y
+	k.sum = t
+}
+
+// newRand creates a random number generator seeded from the current time.
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// Errors returned when a weighted array can't be sampled from.
+// Compare against them with errors.Is.
+var (
+	// ErrEmpty is returned for an array with no items.
+	ErrEmpty = errors.New("Array of items must be longer than 0.")
+	// ErrNonPositiveWeight is returned when any item has a weight of zero or less.
+	ErrNonPositiveWeight = errors.New("All items must have a positive weight.")
+	// ErrNegativeCount is returned when a negative number of samples is requested.
+	ErrNegativeCount = errors.New("Number of samples must not be negative.")
+	// ErrTooMany is returned when more distinct items are requested than exist.
+	ErrTooMany = errors.New("Cannot draw more distinct items than the array holds.")
+	// ErrLengthMismatch is returned when parallel slices differ in length.
+	ErrLengthMismatch = errors.New("Items and weights must be the same length.")
+	// ErrWeightOverflow is returned when a weight, or the sum of all weights,
+	// is too large to represent.
+	ErrWeightOverflow = errors.New("Total weight of all items is too large.")
+	// ErrNegativeIndex is returned when any item has an index below zero.
+	ErrNegativeIndex = errors.New("All items must have a non-negative index.")
+	// ErrIndexNotFound is returned when no item has the requested index.
+	ErrIndexNotFound = errors.New("No item has the requested index.")
+	// ErrDuplicateIndex is returned when two items share the same index.
+	ErrDuplicateIndex = errors.New("All items must have a unique index.")
+	// ErrNonPositiveScale is returned when a scale factor is zero or less.
+	ErrNonPositiveScale = errors.New("Scale must be positive.")
+	// ErrOutOfRange is returned when an argument is outside the range
+	// allowed by the function it was passed to.
+	ErrOutOfRange = errors.New("Argument is outside the allowed range.")
+	// ErrExhausted is returned when every item in a pool has been used up.
+	ErrExhausted = errors.New("All items in the pool have been used up.")
+	// ErrNotSorted is returned in debug builds when an array
+	// passed as presorted isn't in ascending order.
+	ErrNotSorted = errors.New("Items must be sorted ascending by weight.")
+	// ErrNotNormalized is returned when probabilities don't add up to one.
+	ErrNotNormalized = errors.New("Probabilities must sum to 1.")
+	// ErrMaxTries is returned when no draw was accepted
+	// within the allowed number of tries.
+	ErrMaxTries = errors.New("No sample was accepted within the allowed number of tries.")
+	// ErrInvalidConfig is returned when a sampler configuration
+	// combines settings that can't be used together.
+	ErrInvalidConfig = errors.New("Sampler configuration is invalid.")
+)
+
+// Validate checks that the array can be built into a CDF: it must be
+// non-empty, every weight must be positive and no two items may share
+// an index. It returns the same errors BuildCDF would, without paying
+// to sort and accumulate the weights.
+func (s WeightedItems) Validate() error {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return ErrEmpty
+	}
+
+	// Make sure all items have positive weight
+	for _, item := range s {
+		if item.Weight <= 0 {
+			return ErrNonPositiveWeight
+		}
+	}
+
+	// Reject items that point to the same index
+	if hasDuplicateIndex(len(s), func(i int) int { return s[i].Index }) {
+		return ErrDuplicateIndex
+	}
+
+	return nil
+}
+
+// Validate checks that the array can be built into a CDF: it must be
+// non-empty, every weight must be positive and no two items may share
+// an index. It returns the same errors BuildCDF would, without paying
+// to sort and accumulate the weights.
+func (s WeightedItemsFloat) Validate() error {
 	// Reject empty arrays
 	if len(s) <= 0 {
-		return nil, errors.New(tooShortErr)
+		return ErrEmpty
+	}
+
+	// Make sure all items have positive weight, including
+	// rejecting NaN, which fails every comparison
+	for _, item := range s {
+		if !(item.Weight > 0) {
+			return ErrNonPositiveWeight
+		}
+	}
+
+	// Reject items that point to the same index
+	if hasDuplicateIndex(len(s), func(i int) int { return s[i].Index }) {
+		return ErrDuplicateIndex
+	}
+
+	return nil
+}
+
+// cumulative returns a sorted copy of the array where each weight
+// has been replaced by the running total up to and including that item.
+func (s WeightedItems) cumulative() (WeightedItems, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
 	}
 
+	// Work on a copy so the caller's weights and order are preserved
+	s = append(make(WeightedItems, 0, len(s)), s...)
+
 	// Sort the array ascending by weight
-	sort.Sort(s)
+	sortAscending(s)
 
-	// Make sure first item has positive weight
-	if s[0].Weight <= 0 {
-		return nil, errors.New(zeroWeightErr)
+	if err := s.accumulate(); err != nil {
+		return nil, err
 	}
+	return s, nil
+}
 
-	// Accumulate the weights
-	for i := 1; i < len(s); i++ {
-		// Make sure all items have positive weight
-		if s[i].Weight <= 0 {
-			return nil, errors.New(zeroWeightErr)
+// sortAscending sorts data by Less. Arrays that are already ascending
+// are left alone and arrays that are strictly descending, a common
+// most-important-first layout, are reversed instead of re-sorted; the
+// result is the same as sort.Sort either way.
+func sortAscending(data sort.Interface) {
+	if sort.IsSorted(data) {
+		return
+	}
+
+	n := data.Len()
+	for i := 1; i < n; i++ {
+		if !data.Less(i, i-1) {
+			sort.Sort(data)
+			return
 		}
+	}
 
-		s[i].Weight += s[i-1].Weight
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		data.Swap(i, j)
 	}
+}
 
-	// Initialize random number generator
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	searchCDF := func() int {
-		// Picking a random number in the range [1, max weight + 1)
-		num := r.Intn(s[len(s)-1].Weight) + 1
-
-		// Binary search! Look for the number generated.
-		// Right and left are the bounds for the binary search
-		right := len(s) - 1
-		left := 0
-
-		for {
-			// check the middle of the bounds
-			m := (left + right) / 2 // m stands for middle
-			valm := s[m].Weight
-
-			if valm == num { // exact match
-				return s[m].Index
-			} else if valm < num {
-				// Middle item is less than number
-
-				if m == len(s)-1 {
-					// only option is rightmost item
-					return s[m].Index
-				} else if s[m+1].Weight > num {
-					// return the right item when
-					// the search is finished
-					// and left between two items.
-					return s[m+1].Index
-				}
-				// bring left bound to the middle
-				left = m + 1
-			} else {
-				// Middle item is more than number
-
-				if m == 0 || s[m-1].Weight <= num {
-					// Can't move left, so return the middle.
-					return s[m].Index
-				}
-				// bring right bound to the middle
-				right = m - 1
-			}
+// accumulate replaces each weight in place with the
+// running total up to and including that item.
+func (s WeightedItems) accumulate() error {
+	for i := 1; i < len(s); i++ {
+		// Make sure the running total can't wrap around
+		if s[i].Weight > math.MaxInt-s[i-1].Weight {
+			return ErrWeightOverflow
 		}
+
+		s[i].Weight += s[i-1].Weight
 	}
-	return searchCDF, nil
+
+	return nil
 }
 
 // BuildCDF converts a weighted array into a function that will return
 // random elements from it, when called.
-// Allows for use of floating-point weights.
-func (s WeightedItemsFloat) BuildCDF() (func() int, error) {
-	// Reject empty arrays
-	if len(s) <= 0 {
-		return nil, errors.New(tooShortErr)
+func (s WeightedItems) BuildCDF() (func() int, error) {
+	return s.BuildCDFWithRand(newRand())
+}
+
+// BuildCDFWithRand works like BuildCDF, but draws from the given
+// random number generator instead of seeding its own.
+// Passing a fixed-seed generator makes the returned sequence reproducible.
+func (s WeightedItems) BuildCDFWithRand(r *rand.Rand) (func() int, error) {
+	c, err := s.build(r)
+	if err != nil {
+		return nil, err
+	}
+	return c.sampler(), nil
+}
+
+// cumulative returns a sorted copy of the array where each weight
+// has been replaced by the running total up to and including that item.
+func (s WeightedItemsFloat) cumulative() (WeightedItemsFloat, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
 	}
 
+	// Work on a copy so the caller's weights and order are preserved
+	s = append(make(WeightedItemsFloat, 0, len(s)), s...)
+
 	// Sort the array ascending by weight
-	sort.Sort(s)
+	sortAscending(s)
 
-	// Make sure first item has positive weight
-	if s[0].Weight <= 0 {
-		return nil, errors.New(zeroWeightErr)
+	if err := s.accumulate(); err != nil {
+		return nil, err
 	}
+	return s, nil
+}
 
-	// Accumulate the weights
+// accumulate replaces each weight in place with the running total up to
+// and including that item, compensating for rounding error so later
+// totals don't drift.
+func (s WeightedItemsFloat) accumulate() error {
+	sum := kahanSum{sum: s[0].Weight}
 	for i := 1; i < len(s); i++ {
-		// Make sure all items have positive weight
-		if s[0].Weight <= 0 {
-			return nil, errors.New(zeroWeightErr)
+		sum.add(s[i].Weight)
+		s[i].Weight = sum.sum
+	}
+
+	// Make sure the random draw can't be scaled to infinity. Once the
+	// sum overflows, adding more items can turn it into NaN instead,
+	// so reject anything that isn't finite
+	if !(sum.sum <= math.MaxFloat64) {
+		return ErrWeightOverflow
+	}
+
+	return nil
+}
+
+// BuildCDF converts a weighted array into a function that will return
+// random elements from it, when called.
+// Allows for use of floating-point weights.
+func (s WeightedItemsFloat) BuildCDF() (func() int, error) {
+	return s.BuildCDFWithRand(newRand())
+}
+
+// BuildCDFWithRand works like BuildCDF, but draws from the given
+// random number generator instead of seeding its own.
+// Passing a fixed-seed generator makes the returned sequence reproducible.
+func (s WeightedItemsFloat) BuildCDFWithRand(r *rand.Rand) (func() int, error) {
+	c, err := s.build(r)
+	if err != nil {
+		return nil, err
+	}
+	return c.sampler(), nil
+}
+
+// BuildCDFSkipZero works like BuildCDF, but drops zero-weight items
+// instead of rejecting them, so they can never be selected.
+// Negative weights are still rejected. If every item is dropped the
+// build fails with ErrEmpty, unless WithEmptySentinel is given.
+func (s WeightedItems) BuildCDFSkipZero(opts ...Option) (func() int, error) {
+	kept := make(WeightedItems, 0, len(s))
+	for _, item := range s {
+		if item.Weight < 0 {
+			return nil, ErrNonPositiveWeight
+		}
+		if item.Weight > 0 {
+			kept = append(kept, item)
 		}
+	}
 
-		s[i].Weight += s[i-1].Weight
+	if len(kept) == 0 && newOptions(opts).emptySentinel {
+		return noSelection, nil
 	}
 
-	// Initialize random number generator
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	searchCDF := func() int {
-		// Picking a random number in the range [1, max weight + 1)
-		num := r.Float64()*(s[len(s)-1].Weight-1) + 1
-
-		// Binary search! Look for the number generated.
-		// Right and left are the bounds for the binary search
-		right := len(s) - 1
-		left := 0
-
-		for {
-			// check the middle of the bounds
-			m := (left + right) / 2 // m stands for middle
-			valm := s[m].Weight
-
-			if math.Abs(valm-num) <= EPSILON { // exact match
-				return s[m].Index
-			} else if valm < num {
-				// Middle item is less than number
-
-				if m == len(s)-1 {
-					// only option is rightmost item
-					return s[m].Index
-				} else if s[m+1].Weight > num {
-					// return the right item when
-					// the search is finished
-					// and left between two items.
-					return s[m+1].Index
-				}
-				// bring left bound to the middle
-				left = m + 1
-			} else {
-				// Middle item is more than number
-
-				if m == 0 || s[m-1].Weight <= num {
-					// Can't move left, so return the middle.
-					return s[m].Index
-				}
-				// bring right bound to the middle
-				right = m - 1
-			}
+	return kept.BuildCDF()
+}
+
+// BuildCDFSkipZero works like BuildCDF, but drops zero-weight items
+// instead of rejecting them, so they can never be selected.
+// Negative weights are still rejected. If every item is dropped the
+// build fails with ErrEmpty, unless WithEmptySentinel is given.
+func (s WeightedItemsFloat) BuildCDFSkipZero(opts ...Option) (func() int, error) {
+	kept := make(WeightedItemsFloat, 0, len(s))
+	for _, item := range s {
+		if item.Weight < 0 {
+			return nil, ErrNonPositiveWeight
+		}
+		if item.Weight > 0 {
+			kept = append(kept, item)
 		}
 	}
-	return searchCDF, nil
+
+	if len(kept) == 0 && newOptions(opts).emptySentinel {
+		return noSelection, nil
+	}
+
+	return kept.BuildCDF()
+}
+
+// BuildCDFPresorted works like BuildCDF, but trusts that the array is
+// already sorted ascending by weight (then index, as Less does) and skips
+// sorting it. Weights are still checked and accumulated as usual.
+//
+// Sampling follows the weights whatever the order; an unsorted array only
+// changes which sequence a given seed produces. Builds with the stairsdebug
+// tag verify the order and return ErrNotSorted if it's wrong.
+func (s WeightedItems) BuildCDFPresorted() (func() int, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	if debug && !sort.IsSorted(s) {
+		return nil, ErrNotSorted
+	}
+
+	// Work on a copy so the caller's weights are preserved
+	c := append(make(WeightedItems, 0, len(s)), s...)
+	if err := c.accumulate(); err != nil {
+		return nil, err
+	}
+
+	return (&CDF{items: c, r: newRand()}).Sample, nil
+}
+
+// BuildCDFPresorted works like BuildCDF, but trusts that the array is
+// already sorted ascending by weight (then index, as Less does) and skips
+// sorting it. Weights are still checked and accumulated as usual.
+//
+// Sampling follows the weights whatever the order; an unsorted array only
+// changes which sequence a given seed produces. Builds with the stairsdebug
+// tag verify the order and return ErrNotSorted if it's wrong.
+func (s WeightedItemsFloat) BuildCDFPresorted() (func() int, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	if debug && !sort.IsSorted(s) {
+		return nil, ErrNotSorted
+	}
+
+	// Work on a copy so the caller's weights are preserved
+	c := append(make(WeightedItemsFloat, 0, len(s)), s...)
+	if err := c.accumulate(); err != nil {
+		return nil, err
+	}
+
+	return (&CDFFloat{items: c, r: newRand()}).Sample, nil
 }
@@ -1,6 +1,11 @@
 package stairs
 
-import "testing"
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
 
 type testWeighted struct {
 	name   string
@@ -72,7 +77,7 @@ func TestEmpty(t *testing.T) {
 
 	_, err := w.BuildCDF()
 
-	if err == nil {
+	if !errors.Is(err, ErrEmpty) {
 		t.Fail()
 	}
 }
@@ -84,7 +89,7 @@ func TestEmptyFloat(t *testing.T) {
 
 	_, err := w.BuildCDF()
 
-	if err == nil {
+	if !errors.Is(err, ErrEmpty) {
 		t.Fail()
 	}
 }
@@ -100,7 +105,7 @@ func TestZeroWeight(t *testing.T) {
 
 	_, err := w.BuildCDF()
 
-	if err == nil {
+	if !errors.Is(err, ErrNonPositiveWeight) {
 		t.Fail()
 	}
 }
@@ -116,7 +121,7 @@ func TestZeroWeightFloat(t *testing.T) {
 
 	_, err := w.BuildCDF()
 
-	if err == nil {
+	if !errors.Is(err, ErrNonPositiveWeight) {
 		t.Fail()
 	}
 }
@@ -132,7 +137,7 @@ func TestNegativeWeight(t *testing.T) {
 
 	_, err := w.BuildCDF()
 
-	if err == nil {
+	if !errors.Is(err, ErrNonPositiveWeight) {
 		t.Fail()
 	}
 }
@@ -148,15 +153,43 @@ func TestNegativeWeightFloat(t *testing.T) {
 
 	_, err := w.BuildCDF()
 
-	if err == nil {
+	if !errors.Is(err, ErrNonPositiveWeight) {
 		t.Fail()
 	}
 }
 
-// // TestDuplicateIndices checks that the builder
-// // rejects a weighted array with two items that point
-// // to the same index.
-// func TestDuplicateIndices
+// TestDuplicateIndices checks that the builder
+// rejects a weighted array with two items that point
+// to the same index.
+func TestDuplicateIndices(t *testing.T) {
+	var w WeightedItems
+
+	w = append(w, WeightedItem{5, 0})
+	w = append(w, WeightedItem{2, 1})
+	w = append(w, WeightedItem{3, 0})
+
+	_, err := w.BuildCDF()
+
+	if !errors.Is(err, ErrDuplicateIndex) {
+		t.Fail()
+	}
+}
+
+// TestDuplicateIndicesFloat checks that the builder
+// rejects a floating-point weighted array with two
+// items that point to the same index.
+func TestDuplicateIndicesFloat(t *testing.T) {
+	var w WeightedItemsFloat
+
+	w = append(w, WeightedItemFloat{3.7473, 2})
+	w = append(w, WeightedItemFloat{1.373, 2})
+
+	_, err := w.BuildCDF()
+
+	if !errors.Is(err, ErrDuplicateIndex) {
+		t.Fail()
+	}
+}
 
 func buildWeightedFloatArray() WeightedItemsFloat {
 	a := make(testFloatWeights, 0)
@@ -207,3 +240,501 @@ func TestSelectItemsFromFloat(t *testing.T) {
 		}
 	}
 }
+
+// TestBuildWithRand checks that two samplers built with
+// identically seeded generators return the same sequence.
+func TestBuildWithRand(t *testing.T) {
+	w := buildWeightedArray()
+
+	f1, err := w.BuildCDFWithRand(rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f2, err := w.BuildCDFWithRand(rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if a, b := f1(), f2(); a != b {
+			t.Fatalf("draw %d: got %d and %d from the same seed", i, a, b)
+		}
+	}
+}
+
+// TestBuildWithRandFloat checks that two floating-point samplers
+// built with identically seeded generators return the same sequence.
+func TestBuildWithRandFloat(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	f1, err := w.BuildCDFWithRand(rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f2, err := w.BuildCDFWithRand(rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if a, b := f1(), f2(); a != b {
+			t.Fatalf("draw %d: got %d and %d from the same seed", i, a, b)
+		}
+	}
+}
+
+// TestBuildPreservesInput checks that building a CDF
+// leaves the caller's weights and order untouched.
+func TestBuildPreservesInput(t *testing.T) {
+	w := WeightedItems{{5, 0}, {1, 1}, {3, 2}}
+	orig := append(WeightedItems(nil), w...)
+
+	if _, err := w.BuildCDF(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range w {
+		if w[i] != orig[i] {
+			t.Errorf("item %d changed from %v to %v", i, orig[i], w[i])
+		}
+	}
+}
+
+// TestBuildPreservesInputFloat checks that building a floating-point
+// CDF leaves the caller's weights and order untouched.
+func TestBuildPreservesInputFloat(t *testing.T) {
+	w := WeightedItemsFloat{{5.5, 0}, {1.25, 1}, {3.75, 2}}
+	orig := append(WeightedItemsFloat(nil), w...)
+
+	if _, err := w.BuildCDF(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range w {
+		if w[i] != orig[i] {
+			t.Errorf("item %d changed from %v to %v", i, orig[i], w[i])
+		}
+	}
+}
+
+// TestWeightOverflow checks that weights whose sum
+// exceeds the int range are rejected instead of wrapping.
+func TestWeightOverflow(t *testing.T) {
+	w := WeightedItems{{math.MaxInt - 1, 0}, {math.MaxInt - 2, 1}}
+
+	_, err := w.BuildCDF()
+
+	if !errors.Is(err, ErrWeightOverflow) {
+		t.Fail()
+	}
+}
+
+// stepSource is a rand.Source whose Int63 values count up in the bits
+// that Intn uses for powers of two, so Intn(8) returns 0, 1, 2 and so on.
+type stepSource struct{ n int64 }
+
+func (s *stepSource) Int63() int64 {
+	v := s.n << 32
+	s.n++
+	return v
+}
+
+func (s *stepSource) Seed(seed int64) {
+	s.n = 0
+}
+
+// TestSearchBoundaries checks that every draw lands on the first
+// cumulative weight at or above it, including a draw equal to the
+// weight just left of the middle item.
+func TestSearchBoundaries(t *testing.T) {
+	f, err := buildWeightedArray().BuildCDFWithRand(rand.New(&stepSource{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Weights 1, 2 and 5 accumulate to 1, 3 and 8, and
+	// the draws go through 1 to 8 in turn
+	want := []int{0, 1, 1, 2, 2, 2, 2, 2}
+	for i, index := range want {
+		if got := f(); got != index {
+			t.Errorf("draw %d: got %d, want %d", i+1, got, index)
+		}
+	}
+}
+
+// TestManyNonPositiveWeightsFloat checks that every weight is checked,
+// not just the first: the bad weights here all come after a positive one,
+// and are passed to Validate unsorted so none is moved to the front.
+// NaN doesn't sort below anything, so BuildCDF has to find it in place.
+func TestManyNonPositiveWeightsFloat(t *testing.T) {
+	var w WeightedItemsFloat
+
+	w = append(w, WeightedItemFloat{2.5, 0})
+	w = append(w, WeightedItemFloat{0, 1})
+	w = append(w, WeightedItemFloat{-0.5, 2})
+	w = append(w, WeightedItemFloat{0, 3})
+	w = append(w, WeightedItemFloat{1.25, 4})
+
+	if err := w.Validate(); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Errorf("got %v from Validate, want ErrNonPositiveWeight", err)
+	}
+
+	nan := WeightedItemsFloat{{2.5, 0}, {1.25, 1}, {math.NaN(), 2}}
+	if _, err := nan.BuildCDF(); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Errorf("got %v from BuildCDF, want ErrNonPositiveWeight", err)
+	}
+}
+
+// TestDistribution checks that both weighted array
+// types can be used through the Distribution interface.
+func TestDistribution(t *testing.T) {
+	for _, d := range []Distribution{buildWeightedArray(), buildWeightedFloatArray()} {
+		f, err := d.BuildCDF()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if index := f(); index < 0 || index >= d.Len() {
+			t.Fail()
+		}
+	}
+}
+
+// TestSkipZero checks that zero-weight items are
+// dropped and never selected.
+func TestSkipZero(t *testing.T) {
+	w := WeightedItems{{0, 0}, {3, 1}, {0, 2}, {1, 3}}
+
+	f, err := w.BuildCDFSkipZero()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if index := f(); index != 1 && index != 3 {
+			t.Fatalf("selected index %d", index)
+		}
+	}
+}
+
+// TestSkipZeroFloat checks that zero-weight items are
+// dropped and never selected, while negatives are still rejected.
+func TestSkipZeroFloat(t *testing.T) {
+	w := WeightedItemsFloat{{0, 0}, {0.5, 1}, {2.25, 2}, {0, 3}}
+
+	f, err := w.BuildCDFSkipZero()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if index := f(); index != 1 && index != 2 {
+			t.Fatalf("selected index %d", index)
+		}
+	}
+
+	w = append(w, WeightedItemFloat{-1, 4})
+	if _, err := w.BuildCDFSkipZero(); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Fail()
+	}
+}
+
+// TestKahanAccumulation checks that accumulating many tiny
+// floating-point weights doesn't drift from the true total.
+func TestKahanAccumulation(t *testing.T) {
+	const n = 1000000
+	const weight = 0.1
+
+	w := make(WeightedItemsFloat, n)
+	for i := range w {
+		w[i] = WeightedItemFloat{weight, i}
+	}
+
+	totals, _, err := w.CumulativeWeights()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A plain running sum is off by more than 1e-6 here
+	if last := totals[n-1]; math.Abs(last-n*weight) > 1e-9 {
+		t.Errorf("last cumulative weight %v, want %v", last, n*weight)
+	}
+}
+
+// TestSmallFirstItemFloat checks that an item whose cumulative
+// weight is below one is still selected in proportion to its weight.
+func TestSmallFirstItemFloat(t *testing.T) {
+	w := WeightedItemsFloat{{0.5, 0}, {1.5, 1}}
+
+	f, err := w.BuildCDFWithRand(rand.New(rand.NewSource(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const draws = 100000
+	counts := SampleHistogram(f, draws, len(w))
+
+	if observed := float64(counts[0]) / draws; math.Abs(observed-0.25) > 0.01 {
+		t.Errorf("small item observed %v of the time, want 0.25", observed)
+	}
+}
+
+// TestEqualWeightsStable checks that equal weights give the same
+// sequence for a fixed seed no matter what order they're passed in.
+func TestEqualWeightsStable(t *testing.T) {
+	a := WeightedItems{{2, 0}, {2, 1}, {2, 2}, {2, 3}, {1, 4}}
+	b := WeightedItems{{2, 3}, {1, 4}, {2, 1}, {2, 0}, {2, 2}}
+
+	fa, err := a.BuildCDFWithRand(rand.New(rand.NewSource(21)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fb, err := b.BuildCDFWithRand(rand.New(rand.NewSource(21)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if x, y := fa(), fb(); x != y {
+			t.Fatalf("draw %d: got %d and %d", i, x, y)
+		}
+	}
+}
+
+// TestEqualWeightsStableFloat checks that equal floating-point weights
+// give the same sequence for a fixed seed regardless of input order.
+func TestEqualWeightsStableFloat(t *testing.T) {
+	a := WeightedItemsFloat{{0.5, 0}, {0.5, 1}, {0.5, 2}, {0.25, 3}}
+	b := WeightedItemsFloat{{0.5, 2}, {0.5, 0}, {0.25, 3}, {0.5, 1}}
+
+	fa, err := a.BuildCDFWithRand(rand.New(rand.NewSource(21)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fb, err := b.BuildCDFWithRand(rand.New(rand.NewSource(21)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if x, y := fa(), fb(); x != y {
+			t.Fatalf("draw %d: got %d and %d", i, x, y)
+		}
+	}
+}
+
+// TestValidate checks that Validate reports the same
+// problems BuildCDF would, without building.
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		w    WeightedItems
+		want error
+	}{
+		{buildWeightedArray(), nil},
+		{WeightedItems{}, ErrEmpty},
+		{WeightedItems{{1, 0}, {-2, 1}}, ErrNonPositiveWeight},
+		{WeightedItems{{1, 0}, {2, 0}}, ErrDuplicateIndex},
+	}
+
+	for _, c := range cases {
+		if err := c.w.Validate(); !errors.Is(err, c.want) {
+			t.Errorf("%v: got %v, want %v", c.w, err, c.want)
+		}
+
+		if _, err := c.w.BuildCDF(); !errors.Is(err, c.want) {
+			t.Errorf("%v: BuildCDF got %v, want %v", c.w, err, c.want)
+		}
+	}
+}
+
+// TestValidateFloat checks that Validate reports the same problems
+// BuildCDF would for floating-point arrays, without building.
+func TestValidateFloat(t *testing.T) {
+	cases := []struct {
+		w    WeightedItemsFloat
+		want error
+	}{
+		{buildWeightedFloatArray(), nil},
+		{WeightedItemsFloat{}, ErrEmpty},
+		{WeightedItemsFloat{{1, 0}, {math.NaN(), 1}}, ErrNonPositiveWeight},
+		{WeightedItemsFloat{{1, 3}, {2, 3}}, ErrDuplicateIndex},
+	}
+
+	for _, c := range cases {
+		if err := c.w.Validate(); !errors.Is(err, c.want) {
+			t.Errorf("%v: got %v, want %v", c.w, err, c.want)
+		}
+
+		if _, err := c.w.BuildCDF(); !errors.Is(err, c.want) {
+			t.Errorf("%v: BuildCDF got %v, want %v", c.w, err, c.want)
+		}
+	}
+}
+
+// TestPresorted checks that presorted arrays build
+// and return indices in range.
+func TestPresorted(t *testing.T) {
+	w := buildWeightedArray()
+
+	f, err := w.BuildCDFPresorted()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if index := f(); index < 0 || index >= len(w) {
+			t.Fail()
+		}
+	}
+
+	fl := buildWeightedFloatArray()
+	if _, err := fl.BuildCDFPresorted(); err != nil {
+		t.Error(err)
+	}
+
+	var empty WeightedItems
+	if _, err := empty.BuildCDFPresorted(); !errors.Is(err, ErrEmpty) {
+		t.Error("built an empty presorted array")
+	}
+}
+
+// TestTwoItemFastPath checks that the two-item sampler
+// returns the same sequence as the general search.
+func TestTwoItemFastPath(t *testing.T) {
+	w := WeightedItems{{3, 0}, {1, 1}}
+
+	f, err := w.BuildCDFWithRand(rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := w.build(rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if got, want := f(), c.Sample(); got != want {
+			t.Fatalf("draw %d: got %d, want %d", i, got, want)
+		}
+	}
+}
+
+// TestTwoItemFastPathFloat checks that the two-item sampler
+// returns the same sequence as the general search.
+func TestTwoItemFastPathFloat(t *testing.T) {
+	w := WeightedItemsFloat{{0.75, 0}, {0.25, 1}}
+
+	f, err := w.BuildCDFWithRand(rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := w.build(rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if got, want := f(), c.Sample(); got != want {
+			t.Fatalf("draw %d: got %d, want %d", i, got, want)
+		}
+	}
+}
+
+// BenchmarkTwoItems measures the two-item fast path.
+func BenchmarkTwoItems(b *testing.B) {
+	f, err := WeightedItems{{3, 0}, {1, 1}}.BuildCDF()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		f()
+	}
+}
+
+// BenchmarkTwoItemsSearch measures the general search on two
+// items, for comparison with BenchmarkTwoItems.
+func BenchmarkTwoItemsSearch(b *testing.B) {
+	c, err := WeightedItems{{3, 0}, {1, 1}}.Build()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		c.Sample()
+	}
+}
+
+// TestSortAscending checks that ascending, descending and
+// shuffled arrays all end up in the same order.
+func TestSortAscending(t *testing.T) {
+	want := WeightedItems{{1, 3}, {2, 0}, {2, 1}, {5, 2}}
+
+	for _, s := range []WeightedItems{
+		{{1, 3}, {2, 0}, {2, 1}, {5, 2}},
+		{{5, 2}, {2, 1}, {2, 0}, {1, 3}},
+		{{2, 0}, {5, 2}, {1, 3}, {2, 1}},
+	} {
+		sortAscending(s)
+		for i := range want {
+			if s[i] != want[i] {
+				t.Errorf("got %v, want %v", s, want)
+				break
+			}
+		}
+	}
+}
+
+// TestDescendingInput checks that a descending array builds
+// the same sampler as an ascending one.
+func TestDescendingInput(t *testing.T) {
+	asc := WeightedItemsFloat{{1.5, 0}, {2.33, 1}, {5.8999, 2}}
+	desc := WeightedItemsFloat{asc[2], asc[1], asc[0]}
+
+	a, err := asc.BuildCDFWithRand(rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := desc.BuildCDFWithRand(rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if a() != d() {
+			t.Fatalf("draw %d differs", i)
+		}
+	}
+}
+
+// TestSingleItem checks that a one-item sampler always returns
+// its index without drawing from the random number generator.
+func TestSingleItem(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	want := rand.New(rand.NewSource(11)).Int63()
+
+	f, err := WeightedItems{{4, 6}}.BuildCDFWithRand(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := WeightedItemsFloat{{0.5, 6}}.BuildCDFWithRand(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if f() != 6 || g() != 6 {
+			t.Fatal("didn't return the only index")
+		}
+	}
+
+	if r.Int63() != want {
+		t.Error("sampling drew from the generator")
+	}
+}
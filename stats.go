@@ -0,0 +1,477 @@
+package stairs
+
+import (
+	"math"
+	"sort"
+)
+
+// total sums the weights of the array, rejecting empty arrays,
+// non-positive weights and sums that overflow an int.
+func (s WeightedItems) total() (int, error) {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return 0, ErrEmpty
+	}
+
+	total := 0
+	for _, item := range s {
+		// Make sure all items have positive weight
+		if item.Weight <= 0 {
+			return 0, ErrNonPositiveWeight
+		}
+
+		// Make sure the running total can't wrap around
+		if item.Weight > math.MaxInt-total {
+			return 0, ErrWeightOverflow
+		}
+		total += item.Weight
+	}
+
+	return total, nil
+}
+
+// total sums the weights of the array, rejecting empty arrays,
+// non-positive or NaN weights and sums that overflow.
+func (s WeightedItemsFloat) total() (float64, error) {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return 0, ErrEmpty
+	}
+
+	var total kahanSum
+	for _, item := range s {
+		// Make sure all items have positive weight, which NaN doesn't
+		if !(item.Weight > 0) {
+			return 0, ErrNonPositiveWeight
+		}
+		total.add(item.Weight)
+	}
+
+	// Make sure the sum is finite, as in accumulate, which
+	// also rules out an overflowed sum that became NaN
+	if !(total.sum <= math.MaxFloat64) {
+		return 0, ErrWeightOverflow
+	}
+
+	return total.sum, nil
+}
+
+// TotalWeight returns the sum of all weights, which is the
+// largest cumulative weight in a CDF built from the array.
+func (s WeightedItems) TotalWeight() (int, error) {
+	return s.total()
+}
+
+// TotalWeight returns the sum of all weights, which is the
+// largest cumulative weight in a CDF built from the array.
+func (s WeightedItemsFloat) TotalWeight() (float64, error) {
+	return s.total()
+}
+
+// Probabilities returns the chance of each item being selected,
+// calculated as its weight over the total weight.
+// The result is indexed by each item's Index field, so it lines up
+// with the original array; positions with no item are zero.
+func (s WeightedItems) Probabilities() ([]float64, error) {
+	total, err := s.total()
+	if err != nil {
+		return nil, err
+	}
+
+	// Size the result to fit the largest index
+	size := 0
+	for _, item := range s {
+		if item.Index < 0 {
+			return nil, ErrNegativeIndex
+		}
+		if item.Index >= size {
+			size = item.Index + 1
+		}
+	}
+
+	p := make([]float64, size)
+	for _, item := range s {
+		p[item.Index] += float64(item.Weight) / float64(total)
+	}
+
+	return p, nil
+}
+
+// ProbabilityOf returns the chance of the item with the given
+// original index being selected. It doesn't need a built CDF.
+func (s WeightedItems) ProbabilityOf(index int) (float64, error) {
+	total, err := s.total()
+	if err != nil {
+		return 0, err
+	}
+
+	weight, found := 0, false
+	for _, item := range s {
+		if item.Index == index {
+			weight += item.Weight
+			found = true
+		}
+	}
+
+	if !found {
+		return 0, ErrIndexNotFound
+	}
+
+	return float64(weight) / float64(total), nil
+}
+
+// TopK returns the original indices of the k heaviest items,
+// in descending order of weight. Ties break by ascending index.
+// If k is larger than the array, every index is returned.
+func (s WeightedItems) TopK(k int) ([]int, error) {
+	if k < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	// Sort a copy heaviest first, leaving the caller's order alone
+	sorted := append(make(WeightedItems, 0, len(s)), s...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Weight != sorted[j].Weight {
+			return sorted[i].Weight > sorted[j].Weight
+		}
+		return sorted[i].Index < sorted[j].Index
+	})
+
+	if k > len(sorted) {
+		k = len(sorted)
+	}
+
+	out := make([]int, k)
+	for i := range out {
+		out[i] = sorted[i].Index
+	}
+
+	return out, nil
+}
+
+// CumulativeWeights returns the running totals a CDF built from the
+// array searches through, in the same sorted order the sampler uses,
+// along with the original index of the item that ends at each total.
+// It doesn't build the sampler itself.
+func (s WeightedItems) CumulativeWeights() ([]int, []int, error) {
+	c, err := s.cumulative()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totals := make([]int, len(c))
+	indices := make([]int, len(c))
+	for i, item := range c {
+		totals[i] = item.Weight
+		indices[i] = item.Index
+	}
+
+	return totals, indices, nil
+}
+
+// CumulativeWeights returns the running totals a CDF built from the
+// array searches through, in the same sorted order the sampler uses,
+// along with the original index of the item that ends at each total.
+// It doesn't build the sampler itself.
+func (s WeightedItemsFloat) CumulativeWeights() ([]float64, []int, error) {
+	c, err := s.cumulative()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totals := make([]float64, len(c))
+	indices := make([]int, len(c))
+	for i, item := range c {
+		totals[i] = item.Weight
+		indices[i] = item.Index
+	}
+
+	return totals, indices, nil
+}
+
+// Entropy returns the Shannon entropy of the normalized weights, in bits.
+// A uniform array of n items has entropy log2(n); an array dominated
+// by a single item has entropy close to zero.
+func (s WeightedItems) Entropy() (float64, error) {
+	total, err := s.total()
+	if err != nil {
+		return 0, err
+	}
+
+	h := 0.0
+	for _, item := range s {
+		p := float64(item.Weight) / float64(total)
+		h -= p * math.Log2(p)
+	}
+
+	return h, nil
+}
+
+// Entropy returns the Shannon entropy of the normalized weights, in bits.
+// A uniform array of n items has entropy log2(n); an array dominated
+// by a single item has entropy close to zero.
+func (s WeightedItemsFloat) Entropy() (float64, error) {
+	total, err := s.total()
+	if err != nil {
+		return 0, err
+	}
+
+	h := 0.0
+	for _, item := range s {
+		p := item.Weight / total
+		h -= p * math.Log2(p)
+	}
+
+	return h, nil
+}
+
+// Intervals returns, for each original index, the [start, end) range of
+// the unit interval that the item covers, in the same sorted order the
+// sampler accumulates weights. The width of each range is the item's
+// probability, so the ranges can be drawn as a stacked bar.
+func (s WeightedItems) Intervals() (map[int][2]float64, error) {
+	c, err := s.cumulative()
+	if err != nil {
+		return nil, err
+	}
+
+	total := float64(c[len(c)-1].Weight)
+	out := make(map[int][2]float64, len(c))
+
+	start := 0.0
+	for _, item := range c {
+		end := float64(item.Weight) / total
+		out[item.Index] = [2]float64{start, end}
+		start = end
+	}
+
+	return out, nil
+}
+
+// Intervals returns, for each original index, the [start, end) range of
+// the unit interval that the item covers, in the same sorted order the
+// sampler accumulates weights. The width of each range is the item's
+// probability, so the ranges can be drawn as a stacked bar.
+func (s WeightedItemsFloat) Intervals() (map[int][2]float64, error) {
+	c, err := s.cumulative()
+	if err != nil {
+		return nil, err
+	}
+
+	total := c[len(c)-1].Weight
+	out := make(map[int][2]float64, len(c))
+
+	start := 0.0
+	for _, item := range c {
+		end := item.Weight / total
+		out[item.Index] = [2]float64{start, end}
+		start = end
+	}
+
+	return out, nil
+}
+
+// RankedProbability is an item's original index
+// and its chance of being selected.
+type RankedProbability struct {
+	Index       int
+	Probability float64
+}
+
+// RankedProbabilities returns every item's probability, ordered
+// from most to least likely. Ties break by ascending index.
+func (s WeightedItems) RankedProbabilities() ([]RankedProbability, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	total, err := s.total()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]RankedProbability, len(s))
+	for i, item := range s {
+		out[i] = RankedProbability{item.Index, float64(item.Weight) / float64(total)}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Probability != out[j].Probability {
+			return out[i].Probability > out[j].Probability
+		}
+		return out[i].Index < out[j].Index
+	})
+
+	return out, nil
+}
+
+// MostLikely returns the original index of the heaviest item, breaking
+// ties by the smallest index. It neither builds a CDF nor allocates.
+func (s WeightedItems) MostLikely() (int, error) {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return 0, ErrEmpty
+	}
+
+	best := s[0]
+	for _, item := range s[1:] {
+		if item.Weight > best.Weight || item.Weight == best.Weight && item.Index < best.Index {
+			best = item
+		}
+	}
+
+	return best.Index, nil
+}
+
+// LeastLikely returns the original index of the lightest item, breaking
+// ties by the smallest index. It neither builds a CDF nor allocates.
+func (s WeightedItems) LeastLikely() (int, error) {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return 0, ErrEmpty
+	}
+
+	best := s[0]
+	for _, item := range s[1:] {
+		if item.Weight < best.Weight || item.Weight == best.Weight && item.Index < best.Index {
+			best = item
+		}
+	}
+
+	return best.Index, nil
+}
+
+// ExpectedValue returns the sum of each value times its item's probability,
+// where values[i] belongs to s[i]. It's the analytic mean to compare
+// against a Monte Carlo estimate.
+func (s WeightedItems) ExpectedValue(values []float64) (float64, error) {
+	if len(values) != len(s) {
+		return 0, ErrLengthMismatch
+	}
+
+	total, err := s.total()
+	if err != nil {
+		return 0, err
+	}
+
+	var sum kahanSum
+	for i, item := range s {
+		sum.add(float64(item.Weight) / float64(total) * values[i])
+	}
+
+	return sum.sum, nil
+}
+
+// UnreachableItems returns the original indices of items whose range of
+// the cumulative distribution is narrower than the spacing between the
+// random numbers the sampler draws (the total weight divided by 2^53),
+// in the sorted order the sampler uses. These items can't be reliably
+// selected, and those whose weight was lost entirely to rounding can
+// never be. An empty result means every item is reachable.
+func (s WeightedItemsFloat) UnreachableItems() ([]int, error) {
+	c, err := s.cumulative()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sample draws Float64() * total, and Float64 returns multiples of 2^-53
+	spacing := c[len(c)-1].Weight * 0x1p-53
+
+	var out []int
+	prev := 0.0
+	for _, item := range c {
+		if item.Weight-prev < spacing {
+			out = append(out, item.Index)
+		}
+		prev = item.Weight
+	}
+
+	return out, nil
+}
+
+// PercentileIndex returns the original index of the item whose range of
+// the cumulative distribution contains the percentile p, between 0 and
+// 100, in the same sorted order the sampler accumulates weights. A p on
+// the boundary between two items belongs to the item that ends there.
+func (s WeightedItems) PercentileIndex(p float64) (int, error) {
+	if !(p >= 0 && p <= 100) {
+		return 0, ErrOutOfRange
+	}
+
+	c, err := s.cumulative()
+	if err != nil {
+		return 0, err
+	}
+
+	total := float64(c[len(c)-1].Weight)
+	i := sort.Search(len(c)-1, func(i int) bool {
+		return float64(c[i].Weight)/total*100 >= p
+	})
+
+	return c[i].Index, nil
+}
+
+// PercentileIndex returns the original index of the item whose range of
+// the cumulative distribution contains the percentile p, between 0 and
+// 100, in the same sorted order the sampler accumulates weights. A p on
+// the boundary between two items belongs to the item that ends there.
+func (s WeightedItemsFloat) PercentileIndex(p float64) (int, error) {
+	if !(p >= 0 && p <= 100) {
+		return 0, ErrOutOfRange
+	}
+
+	c, err := s.cumulative()
+	if err != nil {
+		return 0, err
+	}
+
+	total := c[len(c)-1].Weight
+	i := sort.Search(len(c)-1, func(i int) bool {
+		return c[i].Weight/total*100 >= p
+	})
+
+	return c[i].Index, nil
+}
+
+// KLDivergence returns the Kullback-Leibler divergence, in nats, of other
+// from the receiver: the sum of p*log(p/q) over the receiver's indices,
+// where p and q are an index's probabilities in the receiver and other.
+// It's zero when the two weightings are proportional and grows as they
+// drift apart. Indices only in other contribute nothing, since their p
+// is zero; an index only in the receiver has a q of zero, where the
+// divergence is undefined, and is reported as ErrIndexNotFound.
+func (s WeightedItemsFloat) KLDivergence(other WeightedItemsFloat) (float64, error) {
+	for _, w := range []WeightedItemsFloat{s, other} {
+		if err := w.Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	ptotal, err := s.total()
+	if err != nil {
+		return 0, err
+	}
+
+	qtotal, err := other.total()
+	if err != nil {
+		return 0, err
+	}
+
+	q := make(map[int]float64, len(other))
+	for _, item := range other {
+		q[item.Index] = item.Weight / qtotal
+	}
+
+	var kl kahanSum
+	for _, item := range s {
+		qi, ok := q[item.Index]
+		if !ok {
+			return 0, ErrIndexNotFound
+		}
+
+		p := item.Weight / ptotal
+		kl.add(p * math.Log(p/qi))
+	}
+
+	return kl.sum, nil
+}
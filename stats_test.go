@@ -0,0 +1,383 @@
+package stairs
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestProbabilities checks that probabilities are
+// lined up with the original indices and sum to one.
+func TestProbabilities(t *testing.T) {
+	w := WeightedItems{{5, 2}, {1, 0}, {2, 1}}
+
+	p, err := w.Probabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float64{0.125, 0.25, 0.625}
+	if len(p) != len(want) {
+		t.Fatalf("got %d probabilities, want %d", len(p), len(want))
+	}
+
+	for i := range want {
+		if math.Abs(p[i]-want[i]) > EPSILON {
+			t.Errorf("index %d: got %v, want %v", i, p[i], want[i])
+		}
+	}
+}
+
+// TestProbabilitiesSparse checks that indices missing
+// from the array are reported with zero probability.
+func TestProbabilitiesSparse(t *testing.T) {
+	w := WeightedItems{{1, 0}, {1, 3}}
+
+	p, err := w.Probabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p) != 4 || p[1] != 0 || p[2] != 0 || p[0] != 0.5 || p[3] != 0.5 {
+		t.Errorf("got %v", p)
+	}
+}
+
+// TestProbabilitiesEmpty checks that an empty array is rejected.
+func TestProbabilitiesEmpty(t *testing.T) {
+	var w WeightedItems
+
+	if _, err := w.Probabilities(); !errors.Is(err, ErrEmpty) {
+		t.Fail()
+	}
+}
+
+// TestProbabilityOf checks the probability reported for a single index.
+func TestProbabilityOf(t *testing.T) {
+	w := buildWeightedArray()
+
+	p, err := w.ProbabilityOf(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(p-0.625) > EPSILON {
+		t.Errorf("got %v, want 0.625", p)
+	}
+}
+
+// TestProbabilityOfMissing checks that asking about
+// an index that isn't in the array is rejected.
+func TestProbabilityOfMissing(t *testing.T) {
+	w := buildWeightedArray()
+
+	if _, err := w.ProbabilityOf(7); !errors.Is(err, ErrIndexNotFound) {
+		t.Fail()
+	}
+}
+
+// TestTopK checks that the heaviest items come back
+// in order, with ties broken by index.
+func TestTopK(t *testing.T) {
+	w := WeightedItems{{3, 4}, {9, 1}, {3, 2}, {1, 0}, {5, 3}}
+
+	out, err := w.TopK(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int{1, 3, 2}
+	if len(out) != len(want) {
+		t.Fatalf("got %v, want %v", out, want)
+	}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Fatalf("got %v, want %v", out, want)
+		}
+	}
+}
+
+// TestTopKClamp checks that asking for more items than
+// exist returns all of them, and that negative k is rejected.
+func TestTopKClamp(t *testing.T) {
+	w := buildWeightedArray()
+
+	out, err := w.TopK(10)
+	if err != nil || len(out) != len(w) {
+		t.Errorf("got %v, %v", out, err)
+	}
+
+	if _, err := w.TopK(-1); !errors.Is(err, ErrNegativeCount) {
+		t.Error("accepted a negative k")
+	}
+}
+
+// TestTotalWeight checks the sums reported for both array types.
+func TestTotalWeight(t *testing.T) {
+	total, err := buildWeightedArray().TotalWeight()
+	if err != nil || total != 8 {
+		t.Errorf("got %d, %v, want 8", total, err)
+	}
+
+	totalFloat, err := buildWeightedFloatArray().TotalWeight()
+	if err != nil || math.Abs(totalFloat-9.7299) > EPSILON {
+		t.Errorf("got %v, %v, want 9.7299", totalFloat, err)
+	}
+
+	var w WeightedItems
+	if _, err := w.TotalWeight(); !errors.Is(err, ErrEmpty) {
+		t.Error("summed an empty array")
+	}
+}
+
+// TestTotalWeightFloatInvalid checks that NaN weights and
+// sums that overflow are rejected.
+func TestTotalWeightFloatInvalid(t *testing.T) {
+	w := WeightedItemsFloat{{1, 0}, {math.NaN(), 1}}
+	if _, err := w.TotalWeight(); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Errorf("got %v for a NaN weight, want ErrNonPositiveWeight", err)
+	}
+
+	w = WeightedItemsFloat{{math.MaxFloat64, 0}, {math.MaxFloat64, 1}}
+	if _, err := w.TotalWeight(); !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("got %v for an infinite sum, want ErrWeightOverflow", err)
+	}
+
+	// A third item turns the overflowed sum into NaN
+	w = append(w, WeightedItemFloat{math.MaxFloat64, 2})
+	if _, err := w.TotalWeight(); !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("got %v for a sum that overflowed to NaN, want ErrWeightOverflow", err)
+	}
+	if _, err := w.Entropy(); !errors.Is(err, ErrWeightOverflow) {
+		t.Errorf("got %v from Entropy, want ErrWeightOverflow", err)
+	}
+}
+
+// TestCumulativeWeights checks the running totals and
+// the sorted order of indices they belong to.
+func TestCumulativeWeights(t *testing.T) {
+	w := WeightedItems{{5, 0}, {1, 1}, {2, 2}}
+
+	totals, indices, err := w.CumulativeWeights()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTotals := []int{1, 3, 8}
+	wantIndices := []int{1, 2, 0}
+	for i := range wantTotals {
+		if totals[i] != wantTotals[i] || indices[i] != wantIndices[i] {
+			t.Fatalf("got %v and %v", totals, indices)
+		}
+	}
+
+	// The caller's array must be left alone
+	if w[0] != (WeightedItem{5, 0}) {
+		t.Errorf("array changed to %v", w)
+	}
+}
+
+// TestCumulativeWeightsFloat checks the running totals
+// of a floating-point array.
+func TestCumulativeWeightsFloat(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	totals, indices, err := w.CumulativeWeights()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTotals := []float64{1.5, 3.83, 9.7299}
+	for i := range wantTotals {
+		if math.Abs(totals[i]-wantTotals[i]) > EPSILON || indices[i] != i {
+			t.Fatalf("got %v and %v", totals, indices)
+		}
+	}
+}
+
+// TestEntropy checks the entropy of uniform and peaked arrays.
+func TestEntropy(t *testing.T) {
+	uniform := WeightedItems{{3, 0}, {3, 1}, {3, 2}, {3, 3}}
+
+	h, err := uniform.Entropy()
+	if err != nil || math.Abs(h-2) > EPSILON {
+		t.Errorf("uniform: got %v, %v, want 2", h, err)
+	}
+
+	peaked := WeightedItemsFloat{{1e-9, 0}, {1e-9, 1}, {1000, 2}}
+
+	h, err = peaked.Entropy()
+	if err != nil || h > 0.001 {
+		t.Errorf("peaked: got %v, %v, want about 0", h, err)
+	}
+}
+
+// TestIntervals checks that each index covers its
+// share of the unit interval in sorted order.
+func TestIntervals(t *testing.T) {
+	w := WeightedItems{{5, 0}, {1, 1}, {2, 2}}
+
+	iv, err := w.Intervals()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int][2]float64{1: {0, 0.125}, 2: {0.125, 0.375}, 0: {0.375, 1}}
+	for index, r := range want {
+		got := iv[index]
+		if math.Abs(got[0]-r[0]) > EPSILON || math.Abs(got[1]-r[1]) > EPSILON {
+			t.Errorf("index %d: got %v, want %v", index, got, r)
+		}
+	}
+}
+
+// TestIntervalsFloat checks that floating-point intervals
+// cover the whole unit interval.
+func TestIntervalsFloat(t *testing.T) {
+	iv, err := buildWeightedFloatArray().Intervals()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if iv[0][0] != 0 || iv[0][1] != iv[1][0] || iv[1][1] != iv[2][0] || math.Abs(iv[2][1]-1) > EPSILON {
+		t.Errorf("got %v", iv)
+	}
+}
+
+// TestRankedProbabilities checks that items come back most
+// likely first, with ties broken by index.
+func TestRankedProbabilities(t *testing.T) {
+	w := WeightedItems{{1, 3}, {2, 2}, {5, 0}, {2, 1}}
+
+	ranked, err := w.RankedProbabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []RankedProbability{{0, 0.5}, {1, 0.2}, {2, 0.2}, {3, 0.1}}
+	for i := range want {
+		if ranked[i].Index != want[i].Index || math.Abs(ranked[i].Probability-want[i].Probability) > EPSILON {
+			t.Fatalf("got %v, want %v", ranked, want)
+		}
+	}
+}
+
+// TestMostLikely checks that the heaviest item is found,
+// with ties broken by the smallest index.
+func TestMostLikely(t *testing.T) {
+	w := WeightedItems{{3, 4}, {7, 6}, {1, 0}, {7, 2}}
+
+	if index, err := w.MostLikely(); err != nil || index != 2 {
+		t.Errorf("got %d, %v, want 2", index, err)
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { w.MostLikely() }); allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+
+	var empty WeightedItems
+	if _, err := empty.MostLikely(); !errors.Is(err, ErrEmpty) {
+		t.Error("found an item in an empty array")
+	}
+}
+
+// TestLeastLikely checks that the lightest item is found,
+// with ties going to the smallest index.
+func TestLeastLikely(t *testing.T) {
+	w := WeightedItems{{3, 4}, {1, 6}, {7, 0}, {1, 2}}
+
+	if index, err := w.LeastLikely(); err != nil || index != 2 {
+		t.Errorf("got %d, %v, want 2", index, err)
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { w.LeastLikely() }); allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+
+	var empty WeightedItems
+	if _, err := empty.LeastLikely(); !errors.Is(err, ErrEmpty) {
+		t.Error("found an item in an empty array")
+	}
+}
+
+// TestExpectedValue checks the probability-weighted mean of some values.
+func TestExpectedValue(t *testing.T) {
+	w := buildWeightedArray()
+
+	ev, err := w.ExpectedValue([]float64{8, 0, -1.6})
+	if err != nil || math.Abs(ev-0) > EPSILON {
+		t.Errorf("got %v, %v, want 0", ev, err)
+	}
+
+	ev, err = w.ExpectedValue([]float64{1, 1, 1})
+	if err != nil || math.Abs(ev-1) > EPSILON {
+		t.Errorf("got %v, %v, want 1", ev, err)
+	}
+
+	if _, err := w.ExpectedValue([]float64{1}); !errors.Is(err, ErrLengthMismatch) {
+		t.Error("accepted too few values")
+	}
+}
+
+// TestUnreachableItems checks that only items too narrow
+// for a draw to reliably land in are reported.
+func TestUnreachableItems(t *testing.T) {
+	w := WeightedItemsFloat{{1, 0}, {1e-17, 1}, {2, 2}, {1e-20, 3}, {1e-9, 4}}
+
+	out, err := w.UnreachableItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The tiny weights sort first, smallest to largest
+	if len(out) != 2 || out[0] != 3 || out[1] != 1 {
+		t.Errorf("got %v, want [3 1]", out)
+	}
+
+	out, err = buildWeightedFloatArray().UnreachableItems()
+	if err != nil || len(out) != 0 {
+		t.Errorf("got %v, %v; want no unreachable items", out, err)
+	}
+}
+
+// TestPercentileIndex checks percentiles inside and on
+// the boundaries of each item's range.
+func TestPercentileIndex(t *testing.T) {
+	// Sorted, the items cover 0-12.5, 12.5-37.5 and 37.5-100
+	cases := map[float64]int{0: 0, 12.5: 0, 13: 1, 37.5: 1, 90: 2, 100: 2}
+	for p, want := range cases {
+		if got, err := buildWeightedArray().PercentileIndex(p); err != nil || got != want {
+			t.Errorf("PercentileIndex(%v) = %d, %v, want %d", p, got, err, want)
+		}
+	}
+
+	if got, err := (WeightedItemsFloat{{3, 7}, {1, 8}}).PercentileIndex(90); err != nil || got != 7 {
+		t.Errorf("got %d, %v, want 7", got, err)
+	}
+
+	for _, p := range []float64{-1, 100.1, math.NaN()} {
+		if _, err := buildWeightedArray().PercentileIndex(p); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("PercentileIndex(%v): got %v, want ErrOutOfRange", p, err)
+		}
+	}
+}
+
+// TestKLDivergence checks proportional, differing
+// and non-overlapping distributions.
+func TestKLDivergence(t *testing.T) {
+	p := WeightedItemsFloat{{1, 0}, {1, 1}}
+
+	if kl, err := p.KLDivergence(WeightedItemsFloat{{5, 1}, {5, 0}}); err != nil || math.Abs(kl) > EPSILON {
+		t.Errorf("got %v, %v; want 0 for proportional weights", kl, err)
+	}
+
+	// 0.5*log(0.5/0.25) + 0.5*log(0.5/0.75)
+	want := 0.5*math.Log(2) + 0.5*math.Log(2.0/3)
+	if kl, err := p.KLDivergence(WeightedItemsFloat{{1, 0}, {3, 1}}); err != nil || math.Abs(kl-want) > EPSILON {
+		t.Errorf("got %v, %v; want %v", kl, err, want)
+	}
+
+	if _, err := p.KLDivergence(WeightedItemsFloat{{1, 0}, {1, 2}}); !errors.Is(err, ErrIndexNotFound) {
+		t.Errorf("got %v, want ErrIndexNotFound", err)
+	}
+}
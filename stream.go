@@ -0,0 +1,119 @@
+package stairs
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// StreamingSampler samples from weighted items that arrive one at a time,
+// when the total number of items isn't known ahead of time.
+//
+// Each added item extends a running total. Because weights are positive,
+// the running totals are already sorted, so adding an item is an amortized
+// O(1) append and sampling is a binary search, with no rebuild in between.
+//
+// The zero value is ready to use and seeds its own generator.
+type StreamingSampler struct {
+	// cumulative holds the running total after each added item
+	cumulative []int
+	// indices holds the original index of each added item
+	indices []int
+	r       *rand.Rand
+}
+
+// NewStreamingSampler creates an empty StreamingSampler that draws from r.
+// If r is nil, a generator seeded from the current time is used.
+func NewStreamingSampler(r *rand.Rand) *StreamingSampler {
+	return &StreamingSampler{r: r}
+}
+
+// Add appends an item with the given weight and original index.
+func (s *StreamingSampler) Add(weight int, index int) error {
+	// Make sure all items have positive weight
+	if weight <= 0 {
+		return ErrNonPositiveWeight
+	}
+
+	total := s.Total()
+
+	// Make sure the running total can't wrap around
+	if weight > math.MaxInt-total {
+		return ErrWeightOverflow
+	}
+
+	s.cumulative = append(s.cumulative, total+weight)
+	s.indices = append(s.indices, index)
+
+	return nil
+}
+
+// Len returns the number of items added so far.
+func (s *StreamingSampler) Len() int {
+	return len(s.indices)
+}
+
+// Total returns the sum of the weights added so far.
+func (s *StreamingSampler) Total() int {
+	if len(s.cumulative) == 0 {
+		return 0
+	}
+	return s.cumulative[len(s.cumulative)-1]
+}
+
+// Sample returns the original index of a random item from those
+// added so far, chosen according to the weights.
+func (s *StreamingSampler) Sample() (int, error) {
+	// Nothing to pick from yet
+	if len(s.indices) == 0 {
+		return 0, ErrEmpty
+	}
+
+	if s.r == nil {
+		s.r = newRand()
+	}
+
+	// Picking a random number in the range [1, total weight + 1)
+	num := s.r.Intn(s.Total()) + 1
+
+	// The first running total at or above the number owns it
+	return s.indices[sort.SearchInts(s.cumulative, num)], nil
+}
+
+// SampleStream starts a goroutine that sends random indices on the
+// returned channel, which has the given buffer size, until ctx is
+// canceled. The channel is closed once the goroutine stops.
+// The CDF mustn't be sampled or reseeded elsewhere while streaming.
+func (c *CDF) SampleStream(ctx context.Context, buffer int) <-chan int {
+	return streamSamples(ctx, buffer, c.Sample)
+}
+
+// SampleStream starts a goroutine that sends random indices on the
+// returned channel, which has the given buffer size, until ctx is
+// canceled. The channel is closed once the goroutine stops.
+// The CDF mustn't be sampled or reseeded elsewhere while streaming.
+func (c *CDFFloat) SampleStream(ctx context.Context, buffer int) <-chan int {
+	return streamSamples(ctx, buffer, c.Sample)
+}
+
+// streamSamples sends draws from sample on a new channel until ctx is canceled.
+func streamSamples(ctx context.Context, buffer int, sample func() int) <-chan int {
+	if buffer < 0 {
+		buffer = 0
+	}
+
+	ch := make(chan int, buffer)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- sample():
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,118 @@
+package stairs
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// TestStreamingSampler checks that items can be sampled
+// between additions and that only added indices are returned.
+func TestStreamingSampler(t *testing.T) {
+	s := NewStreamingSampler(rand.New(rand.NewSource(1)))
+
+	for i, weight := range []int{3, 1, 4, 1, 5} {
+		if err := s.Add(weight, i); err != nil {
+			t.Fatal(err)
+		}
+
+		for j := 0; j < 20; j++ {
+			index, err := s.Sample()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if index < 0 || index > i {
+				t.Fatalf("got index %d after adding %d items", index, i+1)
+			}
+		}
+	}
+
+	if s.Len() != 5 || s.Total() != 14 {
+		t.Errorf("got len %d and total %d", s.Len(), s.Total())
+	}
+}
+
+// TestStreamingSamplerDistribution checks that a heavily
+// weighted item is drawn most of the time.
+func TestStreamingSamplerDistribution(t *testing.T) {
+	var s StreamingSampler
+
+	s.Add(1, 0)
+	s.Add(99, 1)
+
+	count := 0
+	for i := 0; i < 1000; i++ {
+		index, _ := s.Sample()
+		if index == 1 {
+			count++
+		}
+	}
+
+	if count < 900 {
+		t.Errorf("heavy item drawn %d times out of 1000", count)
+	}
+}
+
+// TestStreamingSamplerEmpty checks that sampling before
+// anything has been added is rejected.
+func TestStreamingSamplerEmpty(t *testing.T) {
+	var s StreamingSampler
+
+	if _, err := s.Sample(); !errors.Is(err, ErrEmpty) {
+		t.Fail()
+	}
+}
+
+// TestStreamingSamplerZeroWeight checks that
+// non-positive weights are rejected when added.
+func TestStreamingSamplerZeroWeight(t *testing.T) {
+	var s StreamingSampler
+
+	if err := s.Add(0, 0); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Fail()
+	}
+}
+
+// TestSampleStream checks that the channel produces valid
+// indices and is closed once the context is canceled.
+func TestSampleStream(t *testing.T) {
+	c, err := buildWeightedArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := c.SampleStream(ctx, 8)
+
+	for i := 0; i < 100; i++ {
+		if index := <-ch; index < 0 || index > 2 {
+			t.Fatalf("got index %d", index)
+		}
+	}
+
+	cancel()
+
+	// Drain whatever was buffered; the loop ends when the channel closes
+	for range ch {
+	}
+}
+
+// TestSampleStreamFloat checks that a floating-point CDF
+// streams valid indices.
+func TestSampleStreamFloat(t *testing.T) {
+	c, err := buildWeightedFloatArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := c.SampleStream(ctx, 0)
+	for i := 0; i < 10; i++ {
+		if index := <-ch; index < 0 || index > 2 {
+			t.Fatalf("got index %d", index)
+		}
+	}
+}
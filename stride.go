@@ -0,0 +1,50 @@
+package stairs
+
+// StrideSampler hands out indices in proportion to their weights without
+// any randomness, spreading each item's turns evenly through the cycle
+// instead of in bursts. Over every run of total-weight calls to Next,
+// each index is returned exactly as many times as its weight.
+//
+// It uses smooth weighted round-robin: every call adds each item's weight
+// to its credit, returns the item with the most credit (the earliest in
+// the array on ties) and takes the total weight back from it.
+//
+// A StrideSampler is not safe for concurrent use.
+type StrideSampler struct {
+	items  WeightedItems
+	credit []int
+	total  int
+}
+
+// NewStrideSampler creates a StrideSampler over the items,
+// which are validated like in BuildCDF.
+func NewStrideSampler(items WeightedItems) (*StrideSampler, error) {
+	if err := items.Validate(); err != nil {
+		return nil, err
+	}
+
+	total, err := items.total()
+	if err != nil {
+		return nil, err
+	}
+
+	return &StrideSampler{
+		items:  append(make(WeightedItems, 0, len(items)), items...),
+		credit: make([]int, len(items)),
+		total:  total,
+	}, nil
+}
+
+// Next returns the original index of the next item in the schedule.
+func (s *StrideSampler) Next() int {
+	best := 0
+	for i, item := range s.items {
+		s.credit[i] += item.Weight
+		if s.credit[i] > s.credit[best] {
+			best = i
+		}
+	}
+
+	s.credit[best] -= s.total
+	return s.items[best].Index
+}
@@ -0,0 +1,35 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestStrideSampler checks the schedule is spread out
+// and matches the weights exactly over each cycle.
+func TestStrideSampler(t *testing.T) {
+	s, err := NewStrideSampler(WeightedItems{{5, 0}, {1, 1}, {1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int{0, 0, 1, 0, 2, 0, 0}
+	for cycle := 0; cycle < 3; cycle++ {
+		for i := range want {
+			if got := s.Next(); got != want[i] {
+				t.Fatalf("cycle %d, call %d: got %d, want %d", cycle, i, got, want[i])
+			}
+		}
+	}
+}
+
+// TestStrideSamplerInvalid checks that the items are validated.
+func TestStrideSamplerInvalid(t *testing.T) {
+	if _, err := NewStrideSampler(nil); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+
+	if _, err := NewStrideSampler(WeightedItems{{1, 0}, {1, 0}}); !errors.Is(err, ErrDuplicateIndex) {
+		t.Errorf("got %v, want ErrDuplicateIndex", err)
+	}
+}
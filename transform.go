@@ -0,0 +1,227 @@
+package stairs
+
+import (
+	"math"
+	"sort"
+)
+
+// BuildCDFNormalized works like BuildCDF, but first shifts every weight
+// by the same amount so the smallest becomes floor, which must be positive.
+// This allows zero and negative scores to be sampled.
+//
+// Shifting changes the relative proportions: items are selected in
+// proportion to their distance above the minimum plus floor, not to
+// their original weights.
+func (s WeightedItemsFloat) BuildCDFNormalized(floor float64) (func() int, error) {
+	w, err := s.normalized(floor)
+	if err != nil {
+		return nil, err
+	}
+	return w.BuildCDF()
+}
+
+// normalized returns a copy with every weight shifted
+// so the smallest one equals floor.
+func (s WeightedItemsFloat) normalized(floor float64) (WeightedItemsFloat, error) {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return nil, ErrEmpty
+	}
+
+	if !(floor > 0) || math.IsInf(floor, 1) {
+		return nil, ErrOutOfRange
+	}
+
+	min := s[0].Weight
+	for _, item := range s[1:] {
+		min = math.Min(min, item.Weight)
+	}
+
+	w := make(WeightedItemsFloat, len(s))
+	for i, item := range s {
+		w[i] = WeightedItemFloat{item.Weight - min + floor, item.Index}
+	}
+
+	return w, nil
+}
+
+// BuildCDFSmoothed works like BuildCDF, but first mixes the weighted
+// distribution with a uniform one, so each item is selected with
+// probability (1-epsilon)*weight/total + epsilon/n. Every item keeps at
+// least an epsilon/n chance however small its weight. epsilon must be
+// between 0 and 1; 0 leaves the distribution unchanged and 1 makes it uniform.
+func (s WeightedItemsFloat) BuildCDFSmoothed(epsilon float64) (func() int, error) {
+	w, err := s.smoothed(epsilon)
+	if err != nil {
+		return nil, err
+	}
+	return w.BuildCDF()
+}
+
+// smoothed returns a copy with each weight replaced by
+// its probability mixed with a uniform distribution.
+func (s WeightedItemsFloat) smoothed(epsilon float64) (WeightedItemsFloat, error) {
+	if !(epsilon >= 0 && epsilon <= 1) {
+		return nil, ErrOutOfRange
+	}
+
+	total, err := s.total()
+	if err != nil {
+		return nil, err
+	}
+
+	uniform := epsilon / float64(len(s))
+
+	w := make(WeightedItemsFloat, len(s))
+	for i, item := range s {
+		w[i] = WeightedItemFloat{(1-epsilon)*item.Weight/total + uniform, item.Index}
+	}
+
+	return w, nil
+}
+
+// BuildCDFTempered works like BuildCDF, but first raises each weight to
+// the power 1/temperature. Temperatures above 1 flatten the distribution
+// towards uniform; temperatures below 1 sharpen it towards the heaviest
+// item. temperature must be positive.
+func (s WeightedItemsFloat) BuildCDFTempered(temperature float64) (func() int, error) {
+	w, err := s.tempered(temperature)
+	if err != nil {
+		return nil, err
+	}
+	return w.BuildCDF()
+}
+
+// tempered returns a copy with each weight raised to 1/temperature.
+func (s WeightedItemsFloat) tempered(temperature float64) (WeightedItemsFloat, error) {
+	if !(temperature > 0) || math.IsInf(temperature, 1) {
+		return nil, ErrOutOfRange
+	}
+
+	if _, err := s.total(); err != nil {
+		return nil, err
+	}
+
+	w := make(WeightedItemsFloat, len(s))
+	for i, item := range s {
+		w[i] = WeightedItemFloat{math.Pow(item.Weight, 1/temperature), item.Index}
+	}
+
+	return w, nil
+}
+
+// BuildCDFClamped works like BuildCDF, but first caps every weight at
+// maxRatio times the median weight, so no single item can crowd out the
+// rest. maxRatio must be at least 1.
+func (s WeightedItemsFloat) BuildCDFClamped(maxRatio float64) (func() int, error) {
+	w, err := s.clamped(maxRatio)
+	if err != nil {
+		return nil, err
+	}
+	return w.BuildCDF()
+}
+
+// clamped returns a copy with each weight capped
+// at maxRatio times the median weight.
+func (s WeightedItemsFloat) clamped(maxRatio float64) (WeightedItemsFloat, error) {
+	if !(maxRatio >= 1) {
+		return nil, ErrOutOfRange
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Find the median weight
+	sorted := make([]float64, len(s))
+	for i, item := range s {
+		sorted[i] = item.Weight
+	}
+	sort.Float64s(sorted)
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+
+	limit := maxRatio * median
+
+	w := make(WeightedItemsFloat, len(s))
+	for i, item := range s {
+		w[i] = WeightedItemFloat{math.Min(item.Weight, limit), item.Index}
+	}
+
+	return w, nil
+}
+
+// BuildCDFInverse works like BuildCDF, but selects items in proportion
+// to 1/weight, so each item is chosen with probability
+// (1/weight) / sum(1/w) and the lightest item is the most likely.
+// The weights are promoted to floats first. Zero weights are rejected
+// like in BuildCDF, since they have no inverse.
+func (s WeightedItems) BuildCDFInverse() (func() int, error) {
+	return s.ToFloat().BuildCDFInverse()
+}
+
+// BuildCDFInverse works like BuildCDF, but selects items in proportion
+// to 1/weight, so each item is chosen with probability
+// (1/weight) / sum(1/w) and the lightest item is the most likely.
+// Zero weights are rejected like in BuildCDF, since they have no inverse.
+func (s WeightedItemsFloat) BuildCDFInverse() (func() int, error) {
+	w, err := s.inverted()
+	if err != nil {
+		return nil, err
+	}
+	return w.BuildCDF()
+}
+
+// inverted returns a copy with each weight replaced by its reciprocal.
+func (s WeightedItemsFloat) inverted() (WeightedItemsFloat, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	w := make(WeightedItemsFloat, len(s))
+	for i, item := range s {
+		w[i] = WeightedItemFloat{1 / item.Weight, item.Index}
+	}
+
+	return w, nil
+}
+
+// Transform returns a copy of the array with f applied to every weight,
+// such as math.Log1p to compress a heavy tail. The input weights aren't
+// checked, but every result must be positive and finite: it fails with
+// ErrNonPositiveWeight for results that are zero, negative or NaN, and
+// ErrWeightOverflow for infinite ones.
+func (s WeightedItems) Transform(f func(float64) float64) (WeightedItemsFloat, error) {
+	return s.ToFloat().Transform(f)
+}
+
+// Transform returns a copy of the array with f applied to every weight,
+// such as math.Log1p to compress a heavy tail. The input weights aren't
+// checked, but every result must be positive and finite: it fails with
+// ErrNonPositiveWeight for results that are zero, negative or NaN, and
+// ErrWeightOverflow for infinite ones.
+func (s WeightedItemsFloat) Transform(f func(float64) float64) (WeightedItemsFloat, error) {
+	// Reject empty arrays
+	if len(s) <= 0 {
+		return nil, ErrEmpty
+	}
+
+	w := make(WeightedItemsFloat, len(s))
+	for i, item := range s {
+		weight := f(item.Weight)
+
+		if math.IsInf(weight, 1) {
+			return nil, ErrWeightOverflow
+		}
+		if !(weight > 0) {
+			return nil, ErrNonPositiveWeight
+		}
+
+		w[i] = WeightedItemFloat{weight, item.Index}
+	}
+
+	return w, nil
+}
@@ -0,0 +1,193 @@
+package stairs
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestNormalized checks that negative and zero scores are shifted
+// so the smallest equals the floor.
+func TestNormalized(t *testing.T) {
+	w := WeightedItemsFloat{{-3, 0}, {0, 1}, {2, 2}}
+
+	n, err := w.normalized(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float64{1, 4, 6}
+	for i := range want {
+		if math.Abs(n[i].Weight-want[i]) > EPSILON || n[i].Index != i {
+			t.Errorf("got %v", n)
+		}
+	}
+
+	f, err := w.BuildCDFNormalized(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if index := f(); index < 0 || index >= len(w) {
+			t.Fail()
+		}
+	}
+}
+
+// TestNormalizedBadFloor checks that a non-positive floor is rejected.
+func TestNormalizedBadFloor(t *testing.T) {
+	w := WeightedItemsFloat{{-3, 0}, {2, 1}}
+
+	if _, err := w.BuildCDFNormalized(0); !errors.Is(err, ErrOutOfRange) {
+		t.Fail()
+	}
+}
+
+// TestSmoothed checks that smoothing mixes the
+// probabilities with a uniform distribution.
+func TestSmoothed(t *testing.T) {
+	w := WeightedItemsFloat{{1, 0}, {99, 1}}
+
+	sm, err := w.smoothed(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float64{0.255, 0.745}
+	for i := range want {
+		if math.Abs(sm[i].Weight-want[i]) > EPSILON {
+			t.Errorf("got %v, want %v", sm, want)
+		}
+	}
+
+	if _, err := w.BuildCDFSmoothed(1); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestSmoothedBadEpsilon checks that epsilon outside [0, 1] is rejected.
+func TestSmoothedBadEpsilon(t *testing.T) {
+	w := buildWeightedFloatArray()
+
+	for _, epsilon := range []float64{-0.1, 1.5, math.NaN()} {
+		if _, err := w.BuildCDFSmoothed(epsilon); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("accepted epsilon %v", epsilon)
+		}
+	}
+}
+
+// TestTempered checks that tempering raises each weight to 1/temperature.
+func TestTempered(t *testing.T) {
+	w := WeightedItemsFloat{{4, 0}, {16, 1}}
+
+	flat, err := w.tempered(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(flat[0].Weight-2) > EPSILON || math.Abs(flat[1].Weight-4) > EPSILON {
+		t.Errorf("got %v", flat)
+	}
+
+	sharp, err := w.tempered(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(sharp[0].Weight-16) > EPSILON || math.Abs(sharp[1].Weight-256) > EPSILON {
+		t.Errorf("got %v", sharp)
+	}
+
+	if _, err := w.BuildCDFTempered(0); !errors.Is(err, ErrOutOfRange) {
+		t.Error("accepted a zero temperature")
+	}
+}
+
+// TestClamped checks that weights above the ratio
+// to the median are capped.
+func TestClamped(t *testing.T) {
+	w := WeightedItemsFloat{{1, 0}, {2, 1}, {3, 2}, {1000, 3}}
+
+	c, err := w.clamped(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The median of 1, 2, 3 and 1000 is 2.5, so weights cap at 5
+	want := []float64{1, 2, 3, 5}
+	for i := range want {
+		if c[i].Weight != want[i] {
+			t.Errorf("got %v, want %v", c, want)
+		}
+	}
+
+	if _, err := w.BuildCDFClamped(0.5); !errors.Is(err, ErrOutOfRange) {
+		t.Error("accepted a ratio below 1")
+	}
+}
+
+// TestInverse checks that lighter items become more likely
+// and that zero weights are rejected.
+func TestInverse(t *testing.T) {
+	inv, err := (WeightedItemsFloat{{1, 0}, {4, 1}}).inverted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv[0].Weight != 1 || inv[1].Weight != 0.25 {
+		t.Errorf("got %v", inv)
+	}
+
+	f, err := (WeightedItems{{1, 0}, {4, 1}}).BuildCDFInverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Index 0 should come up 80% of the time
+	counts := SampleHistogram(f, 10000, 2)
+	if counts[0] < 7500 || counts[0] > 8500 {
+		t.Errorf("got %v", counts)
+	}
+
+	if _, err := (WeightedItems{{0, 0}, {1, 1}}).BuildCDFInverse(); !errors.Is(err, ErrNonPositiveWeight) {
+		t.Errorf("got %v, want ErrNonPositiveWeight", err)
+	}
+}
+
+// TestTransform checks that f is applied to every weight
+// and that unusable results are rejected.
+func TestTransform(t *testing.T) {
+	w, err := buildWeightedArray().Transform(math.Sqrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1, math.Sqrt2, math.Sqrt(5)}
+	for i := range want {
+		if w[i].Weight != want[i] || w[i].Index != i {
+			t.Errorf("got %v, want weights %v", w, want)
+		}
+	}
+
+	// Transforming can make non-positive inputs usable
+	if _, err := (WeightedItemsFloat{{0, 0}, {-0.5, 1}}).Transform(math.Exp); err != nil {
+		t.Error(err)
+	}
+
+	tests := []struct {
+		f    func(float64) float64
+		want error
+	}{
+		{func(x float64) float64 { return x - 2 }, ErrNonPositiveWeight},
+		{func(x float64) float64 { return math.NaN() }, ErrNonPositiveWeight},
+		{func(x float64) float64 { return math.Inf(1) }, ErrWeightOverflow},
+	}
+	for _, test := range tests {
+		if _, err := buildWeightedFloatArray().Transform(test.f); !errors.Is(err, test.want) {
+			t.Errorf("got %v, want %v", err, test.want)
+		}
+	}
+
+	if _, err := (WeightedItemsFloat{}).Transform(math.Log1p); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+}
@@ -0,0 +1,30 @@
+package stairs
+
+// SampleUntil draws until pred accepts an index and returns it, giving
+// up with ErrMaxTries after maxTries draws. The result follows the
+// CDF's weights restricted to the indices pred accepts.
+func (c *CDF) SampleUntil(pred func(int) bool, maxTries int) (int, error) {
+	return sampleUntil(c.Sample, pred, maxTries)
+}
+
+// SampleUntil draws until pred accepts an index and returns it, giving
+// up with ErrMaxTries after maxTries draws. The result follows the
+// CDF's weights restricted to the indices pred accepts.
+func (c *CDFFloat) SampleUntil(pred func(int) bool, maxTries int) (int, error) {
+	return sampleUntil(c.Sample, pred, maxTries)
+}
+
+// sampleUntil calls f until pred accepts the result, at most maxTries times.
+func sampleUntil(f func() int, pred func(int) bool, maxTries int) (int, error) {
+	if maxTries < 1 {
+		return 0, ErrOutOfRange
+	}
+
+	for i := 0; i < maxTries; i++ {
+		if index := f(); pred(index) {
+			return index, nil
+		}
+	}
+
+	return 0, ErrMaxTries
+}
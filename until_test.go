@@ -0,0 +1,51 @@
+package stairs
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSampleUntil checks that rejected indices are never returned.
+func TestSampleUntil(t *testing.T) {
+	c, err := buildWeightedArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Reseed(5)
+
+	notTwo := func(index int) bool { return index != 2 }
+	for i := 0; i < 1000; i++ {
+		index, err := c.SampleUntil(notTwo, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index == 2 {
+			t.Fatal("returned a rejected index")
+		}
+	}
+}
+
+// TestSampleUntilExhausted checks that the retry limit is enforced.
+func TestSampleUntilExhausted(t *testing.T) {
+	c, err := buildWeightedFloatArray().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	never := func(int) bool {
+		calls++
+		return false
+	}
+
+	if _, err := c.SampleUntil(never, 10); !errors.Is(err, ErrMaxTries) {
+		t.Errorf("got %v, want ErrMaxTries", err)
+	}
+	if calls != 10 {
+		t.Errorf("got %d tries, want 10", calls)
+	}
+
+	if _, err := c.SampleUntil(never, 0); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, want ErrOutOfRange", err)
+	}
+}
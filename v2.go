@@ -0,0 +1,69 @@
+package stairs
+
+import randv2 "math/rand/v2"
+
+// BuildCDFV2 works like BuildCDF, but the returned function draws its
+// random numbers from math/rand/v2 instead of math/rand. The generator is
+// a PCG, which is faster and statistically stronger than math/rand's
+// original source, seeded from math/rand/v2's top-level generator (a
+// ChaCha8 seeded by the runtime), so separate builds don't repeat each
+// other. The sequence differs from BuildCDF's even for the same seed.
+//
+// BuildCDF is kept as it is, so existing seeded sequences don't change.
+func (s WeightedItems) BuildCDFV2() (func() int, error) {
+	return s.BuildCDFV2WithRand(newRandV2())
+}
+
+// BuildCDFV2WithRand works like BuildCDFV2, but draws from the given
+// math/rand/v2 generator. Passing one built from a fixed-seed source,
+// such as randv2.NewPCG(1, 2), makes the returned sequence reproducible.
+func (s WeightedItems) BuildCDFV2WithRand(r *randv2.Rand) (func() int, error) {
+	c, err := s.build(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	searchCDF := func() int {
+		// Picking a random number in the range [1, max weight + 1)
+		num := r.IntN(c.total()) + 1
+
+		return c.items[c.search(num)].Index
+	}
+	return searchCDF, nil
+}
+
+// BuildCDFV2 works like BuildCDF, but the returned function draws its
+// random numbers from math/rand/v2 instead of math/rand. The generator is
+// a PCG, which is faster and statistically stronger than math/rand's
+// original source, seeded from math/rand/v2's top-level generator (a
+// ChaCha8 seeded by the runtime), so separate builds don't repeat each
+// other. The sequence differs from BuildCDF's even for the same seed.
+//
+// BuildCDF is kept as it is, so existing seeded sequences don't change.
+func (s WeightedItemsFloat) BuildCDFV2() (func() int, error) {
+	return s.BuildCDFV2WithRand(newRandV2())
+}
+
+// BuildCDFV2WithRand works like BuildCDFV2, but draws from the given
+// math/rand/v2 generator. Passing one built from a fixed-seed source,
+// such as randv2.NewPCG(1, 2), makes the returned sequence reproducible.
+func (s WeightedItemsFloat) BuildCDFV2WithRand(r *randv2.Rand) (func() int, error) {
+	c, err := s.build(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	searchCDF := func() int {
+		// Picking a random number in the range [0, max weight)
+		num := r.Float64() * c.total()
+
+		return c.items[c.search(num)].Index
+	}
+	return searchCDF, nil
+}
+
+// newRandV2 returns a PCG generator seeded from math/rand/v2's
+// top-level generator.
+func newRandV2() *randv2.Rand {
+	return randv2.New(randv2.NewPCG(randv2.Uint64(), randv2.Uint64()))
+}
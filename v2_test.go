@@ -0,0 +1,52 @@
+package stairs
+
+import (
+	"errors"
+	randv2 "math/rand/v2"
+	"testing"
+)
+
+// TestBuildV2 checks that the math/rand/v2 sampler follows
+// the weights and is reproducible with a fixed seed.
+func TestBuildV2(t *testing.T) {
+	w := buildWeightedArray()
+
+	f, err := w.BuildCDFV2WithRand(randv2.New(randv2.NewPCG(1, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := w.BuildCDFV2WithRand(randv2.New(randv2.NewPCG(1, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if f() != g() {
+			t.Fatal("same seed gave different sequences")
+		}
+	}
+
+	// Index 2 holds 5 of the 8 units of weight
+	counts := SampleHistogram(f, 8000, 3)
+	if counts[2] < 4600 || counts[2] > 5400 {
+		t.Errorf("got %v", counts)
+	}
+
+	if _, err := (WeightedItems{}).BuildCDFV2(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v, want ErrEmpty", err)
+	}
+}
+
+// TestBuildV2Float checks that the math/rand/v2
+// sampler follows the weights.
+func TestBuildV2Float(t *testing.T) {
+	f, err := WeightedItemsFloat{{1, 0}, {3, 1}}.BuildCDFV2()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := SampleHistogram(f, 8000, 2)
+	if counts[1] < 5600 || counts[1] > 6400 {
+		t.Errorf("got %v", counts)
+	}
+}
@@ -0,0 +1,42 @@
+package stairs
+
+import "math"
+
+// SampleHistogram calls sampleFn draws times and counts how often each
+// index in [0, numIndices) comes up. Indices outside that range are
+// not counted. Comparing the counts against Probabilities gives a quick
+// check that a sampler follows its weights.
+func SampleHistogram(sampleFn func() int, draws int, numIndices int) []int {
+	counts := make([]int, numIndices)
+	for i := 0; i < draws; i++ {
+		if index := sampleFn(); index >= 0 && index < numIndices {
+			counts[index]++
+		}
+	}
+	return counts
+}
+
+// SampleCV calls sampleFn draws times and returns the coefficient of
+// variation of the counts for each index against probs, in the form
+// returned by Probabilities: the root mean square difference between
+// each count and its expected count draws*probs[i], divided by the mean
+// expected count. It shrinks towards zero as draws grow for a sampler
+// that follows probs, whatever their shape. Draws outside
+// [0, len(probs)) count as misses. It returns NaN if draws isn't
+// positive or probs is empty.
+func SampleCV(sampleFn func() int, draws int, probs []float64) float64 {
+	if draws <= 0 || len(probs) == 0 {
+		return math.NaN()
+	}
+
+	counts := SampleHistogram(sampleFn, draws, len(probs))
+
+	var sum kahanSum
+	for i, n := range counts {
+		diff := float64(n) - float64(draws)*probs[i]
+		sum.add(diff * diff)
+	}
+
+	mean := float64(draws) / float64(len(probs))
+	return math.Sqrt(sum.sum/float64(len(probs))) / mean
+}
@@ -0,0 +1,88 @@
+package stairs
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestSampleHistogram compares the empirical frequency of each
+// index against its theoretical probability.
+func TestSampleHistogram(t *testing.T) {
+	w := buildWeightedArray()
+
+	f, err := w.BuildCDFWithRand(rand.New(rand.NewSource(11)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := w.Probabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const draws = 100000
+	counts := SampleHistogram(f, draws, len(w))
+
+	for i, count := range counts {
+		observed := float64(count) / draws
+		if math.Abs(observed-p[i]) > 0.01 {
+			t.Errorf("index %d: observed %v, expected %v", i, observed, p[i])
+		}
+	}
+}
+
+// TestSampleHistogramOutOfRange checks that indices
+// outside the histogram aren't counted.
+func TestSampleHistogramOutOfRange(t *testing.T) {
+	counts := SampleHistogram(func() int { return 5 }, 10, 3)
+
+	for _, count := range counts {
+		if count != 0 {
+			t.Fail()
+		}
+	}
+}
+
+// TestSampleCV checks that samplers following their probabilities have
+// a small coefficient of variation, skewed or not, and that a sampler
+// that doesn't follow them has a large one.
+func TestSampleCV(t *testing.T) {
+	uniformWeights := FromWeights([]int{1, 1, 1, 1})
+	uniform, err := uniformWeights.BuildCDFWithRand(rand.New(rand.NewSource(8)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uniformProbs, err := uniformWeights.Probabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv := SampleCV(uniform, 40000, uniformProbs); cv > 0.05 {
+		t.Errorf("got %v for a uniform sampler", cv)
+	}
+
+	skewedWeights := FromWeights([]int{1, 1, 1, 97})
+	skewed, err := skewedWeights.BuildCDFWithRand(rand.New(rand.NewSource(8)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	skewedProbs, err := skewedWeights.Probabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv := SampleCV(skewed, 40000, skewedProbs); cv > 0.05 {
+		t.Errorf("got %v for a skewed sampler", cv)
+	}
+
+	// A uniform sampler doesn't follow skewed probabilities
+	if cv := SampleCV(uniform, 40000, skewedProbs); cv < 1 {
+		t.Errorf("got %v for a sampler that doesn't follow its probabilities", cv)
+	}
+
+	if cv := SampleCV(uniform, 0, uniformProbs); !math.IsNaN(cv) {
+		t.Errorf("got %v with no draws, want NaN", cv)
+	}
+	if cv := SampleCV(uniform, 100, nil); !math.IsNaN(cv) {
+		t.Errorf("got %v with no probabilities, want NaN", cv)
+	}
+}